@@ -0,0 +1,80 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// AccessLogOption sets an optional parameter for AccessLog
+type AccessLogOption func(*accessLog)
+
+// AccessLogRedact sets the paths of params values replaced by Redacted when
+// the params are logged. See Redact for the path syntax.
+func AccessLogRedact(paths ...string) AccessLogOption {
+	return func(l *accessLog) { l.redact = append(l.redact, paths...) }
+}
+
+// AccessLogSlowThreshold logs the params of calls lasting longer than the
+// threshold. By default, params are never logged.
+func AccessLogSlowThreshold(d time.Duration) AccessLogOption {
+	return func(l *accessLog) { l.slow = d }
+}
+
+type accessLog struct {
+	logger log.Logger
+	redact []string
+	slow   time.Duration
+}
+
+// AccessLog writes one log line per call with method, id, duration, error
+// code, params size, remote address and, for batches, the batch index.
+func AccessLog(logger log.Logger, options ...AccessLogOption) HandlerMiddleware {
+	l := &accessLog{logger: logger}
+	for _, option := range options {
+		option(l)
+	}
+
+	return func(next Handlerer) Handlerer {
+		return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (response json.RawMessage, responseHeader http.Header, err error) {
+			defer func(begin time.Time) {
+				l.log(ctx, params, response, err, time.Since(begin))
+			}(time.Now())
+
+			return next.ServeJSONRPC(ctx, requestHeader, params)
+		})
+	}
+}
+
+func (l *accessLog) log(ctx context.Context, params, response json.RawMessage, err error, duration time.Duration) {
+	method, _ := MethodFromContext(ctx)
+	id, _ := RequestIDFromContext(ctx)
+	info, _ := RequestInfoFromContext(ctx)
+
+	code := 0
+	if err != nil {
+		code = errorResponse(ctx, err).Error.Code
+	} else if e, ok := responseError(response); ok {
+		code = e.Code
+	}
+
+	keyvals := []interface{}{
+		"method", method,
+		"id", formatRequestID(id),
+		"duration", duration,
+		"code", code,
+		"params_size", len(params),
+		"remote_addr", info.RemoteAddr,
+	}
+	if info.Batch {
+		keyvals = append(keyvals, "batch_index", info.BatchIndex)
+	}
+	if l.slow > 0 && duration > l.slow {
+		keyvals = append(keyvals, "slow", true, "params", string(Redact(params, l.redact...)))
+	}
+
+	l.logger.Log(keyvals...)
+}
@@ -0,0 +1,59 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+	"github.com/go-kit/kit/log"
+)
+
+func TestAccessLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.NewLogfmtLogger(buf)
+
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			"fast": HandlererFunc(nopHandler),
+			"slow": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				time.Sleep(20 * time.Millisecond)
+				return nil, nil, jsonrpc.NewInvalidParamsError()
+			}),
+		},
+		jsonrpc.ServerHandlerMiddleware(jsonrpc.AccessLog(
+			logger,
+			jsonrpc.AccessLogRedact("password"),
+			jsonrpc.AccessLogSlowThreshold(10*time.Millisecond),
+		)),
+	)
+
+	body := `[
+		{"jsonrpc":"2.0","method":"fast","params":{"password":"secret"},"id":1},
+		{"jsonrpc":"2.0","method":"slow","params":{"user":"a","password":"secret"},"id":"b"}
+	]`
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.RemoteAddr = "10.0.0.1:1234"
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if got, expect := len(lines), 2; got != expect {
+		t.Fatalf("Expected %d lines, got %d: %s", expect, got, buf.String())
+	}
+
+	expect := []string{
+		`method=fast id=1 duration=\S+ code=0 params_size=21 remote_addr=10.0.0.1:1234 batch_index=0$`,
+		`method=slow id=b duration=\S+ code=-32602 params_size=32 remote_addr=10.0.0.1:1234 batch_index=1 slow=true params="{\\"password\\":\\"\[REDACTED\]\\",\\"user\\":\\"a\\"}"$`,
+	}
+	for i, line := range lines {
+		if !regexp.MustCompile(expect[i]).MatchString(line) {
+			t.Errorf("Line %d: expected %s, got %s", i, expect[i], line)
+		}
+	}
+}
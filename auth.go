@@ -0,0 +1,225 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Principal is the authenticated client
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Roles   []string
+
+	// Claims holds additional information provided by the Authenticator
+	Claims map[string]interface{}
+}
+
+// HasScope reports whether the principal was granted the scope
+func (p *Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+// HasRole reports whether the principal has the role
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// Authenticator authenticates the client before the call is dispatched to
+// the Handlerer. It returns nil Principal and nil error if the request
+// carries no credentials it recognizes. An error rejects the call. It is
+// called once per HTTP request, the calls of a batch share the result.
+type Authenticator interface {
+	Authenticate(ctx context.Context, requestHeader http.Header) (*Principal, error)
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions as
+// Authenticator.
+type AuthenticatorFunc func(ctx context.Context, requestHeader http.Header) (*Principal, error)
+
+// Authenticate implements Authenticator
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, requestHeader http.Header) (*Principal, error) {
+	return f(ctx, requestHeader)
+}
+
+// BearerAuthenticator authenticates the bearer token from the Authorization
+// header with the verify function.
+func BearerAuthenticator(verify func(ctx context.Context, token string) (*Principal, error)) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, requestHeader http.Header) (*Principal, error) {
+		const prefix = "bearer "
+		v := requestHeader.Get("Authorization")
+		if len(v) <= len(prefix) || !strings.EqualFold(v[:len(prefix)], prefix) {
+			return nil, nil
+		}
+		return verify(ctx, strings.TrimSpace(v[len(prefix):]))
+	})
+}
+
+// APIKeyAuthenticator authenticates the API key from the request header with
+// the lookup function.
+func APIKeyAuthenticator(header string, lookup func(ctx context.Context, key string) (*Principal, error)) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, requestHeader http.Header) (*Principal, error) {
+		key := requestHeader.Get(header)
+		if key == "" {
+			return nil, nil
+		}
+		return lookup(ctx, key)
+	})
+}
+
+// MultiAuthenticator tries the authenticators in order and returns the first
+// Principal or error.
+func MultiAuthenticator(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, requestHeader http.Header) (*Principal, error) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(ctx, requestHeader)
+			if err != nil || p != nil {
+				return p, err
+			}
+		}
+		return nil, nil
+	})
+}
+
+// MethodPolicy declares who is allowed to call the method
+type MethodPolicy struct {
+	// Public methods may be called without authentication.
+	Public bool
+
+	// Scopes lists the scopes the principal must have been granted, all of
+	// them.
+	Scopes []string
+
+	// Roles lists the roles allowed to call the method, the principal must
+	// have at least one of them.
+	Roles []string
+}
+
+// allows reports whether the principal may call the method
+func (p MethodPolicy) allows(principal *Principal) bool {
+	for _, scope := range p.Scopes {
+		if !principal.HasScope(scope) {
+			return false
+		}
+	}
+	if len(p.Roles) == 0 {
+		return true
+	}
+	for _, role := range p.Roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerAuthenticator sets the Authenticator run before every call. The
+// Principal is stored in the context and can be retrieved with
+// PrincipalFromContext. Calls without Principal are rejected with
+// UnauthorizedError, unless the method policy is public.
+func ServerAuthenticator(a Authenticator) ServerOption {
+	return func(s *Server) { s.authenticator = a }
+}
+
+// ServerDefaultPolicy sets the policy of methods which have none set with
+// ServerMethodPolicy. NewServer panics if the ServerAuthenticator is not set.
+func ServerDefaultPolicy(p MethodPolicy) ServerOption {
+	return func(s *Server) { s.defaultPolicy = &p }
+}
+
+// ServerMethodPolicy sets the policy checked before the method is called.
+// Calls not allowed by the policy are rejected with ForbiddenError. NewServer
+// panics if the ServerAuthenticator is not set, so the policies cannot be left
+// unenforced.
+func ServerMethodPolicy(method string, p MethodPolicy) ServerOption {
+	return func(s *Server) {
+		if s.methodPolicies == nil {
+			s.methodPolicies = map[string]MethodPolicy{}
+		}
+		s.methodPolicies[method] = p
+	}
+}
+
+// PrincipalFromContext returns the Principal stored by the Server
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKeyPrincipal).(*Principal)
+	return p, ok && p != nil
+}
+
+// PrincipalIdentity identifies the client by the subject of the Principal for
+// rate limiting. Anonymous clients are identified by RemoteAddrIdentity.
+func PrincipalIdentity(ctx context.Context, requestHeader http.Header) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return "principal:" + p.Subject
+	}
+	return RemoteAddrIdentity(ctx, requestHeader)
+}
+
+// authMiddleware authenticates the client and checks the method policy
+func (s *Server) authMiddleware(next Handlerer) Handlerer {
+	if s.authenticator == nil {
+		return next
+	}
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		method, _ := MethodFromContext(ctx)
+		policy, ok := s.methodPolicies[method]
+		if !ok && s.defaultPolicy != nil {
+			policy = *s.defaultPolicy
+		}
+
+		principal, err := s.authenticate(ctx, requestHeader)
+		if err != nil {
+			if e := (Error{}); errors.As(err, &e) {
+				return nil, nil, err
+			}
+			return nil, nil, NewUnauthorizedError()
+		}
+
+		if principal == nil {
+			if !policy.Public {
+				return nil, nil, NewUnauthorizedError()
+			}
+			return next.ServeJSONRPC(ctx, requestHeader, params)
+		}
+
+		if !policy.allows(principal) {
+			return nil, nil, NewForbiddenError()
+		}
+
+		ctx = context.WithValue(ctx, contextKeyPrincipal, principal)
+		return next.ServeJSONRPC(ctx, requestHeader, params)
+	})
+}
+
+// authentication is the result of the Authenticator shared by the calls of
+// the HTTP request
+type authentication struct {
+	once      sync.Once
+	principal *Principal
+	err       error
+}
+
+// authenticate calls the Authenticator once for the HTTP request
+func (s *Server) authenticate(ctx context.Context, requestHeader http.Header) (*Principal, error) {
+	a, ok := ctx.Value(contextKeyAuthentication).(*authentication)
+	if !ok {
+		return s.authenticator.Authenticate(ctx, requestHeader)
+	}
+	a.once.Do(func() {
+		a.principal, a.err = s.authenticator.Authenticate(ctx, requestHeader)
+	})
+	return a.principal, a.err
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
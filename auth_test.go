@@ -0,0 +1,107 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func TestServerAuthenticator(t *testing.T) {
+	tokens := map[string]*jsonrpc.Principal{
+		"admin":  {Subject: "alice", Scopes: []string{"read", "write"}, Roles: []string{"admin"}},
+		"reader": {Subject: "bob", Scopes: []string{"read"}},
+	}
+
+	var decoded bool
+	subject := HandlererFunc(func(ctx context.Context, _ http.Header, _ json.RawMessage) (json.RawMessage, http.Header, error) {
+		decoded = true
+		p, ok := jsonrpc.PrincipalFromContext(ctx)
+		if !ok {
+			return json.RawMessage(`null`), nil, nil
+		}
+		return json.RawMessage(fmt.Sprintf("%q", p.Subject)), nil, nil
+	})
+
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			"read":   subject,
+			"write":  subject,
+			"admin":  subject,
+			"public": subject,
+		},
+		jsonrpc.ServerAuthenticator(jsonrpc.MultiAuthenticator(
+			jsonrpc.BearerAuthenticator(func(_ context.Context, token string) (*jsonrpc.Principal, error) {
+				if p, ok := tokens[token]; ok {
+					return p, nil
+				}
+				return nil, errors.New("invalid token")
+			}),
+			jsonrpc.APIKeyAuthenticator("X-Api-Key", func(_ context.Context, key string) (*jsonrpc.Principal, error) {
+				return &jsonrpc.Principal{Subject: "key:" + key, Scopes: []string{"read"}}, nil
+			}),
+		)),
+		jsonrpc.ServerDefaultPolicy(jsonrpc.MethodPolicy{Scopes: []string{"read"}}),
+		jsonrpc.ServerMethodPolicy("write", jsonrpc.MethodPolicy{Scopes: []string{"read", "write"}}),
+		jsonrpc.ServerMethodPolicy("admin", jsonrpc.MethodPolicy{Roles: []string{"owner", "admin"}}),
+		jsonrpc.ServerMethodPolicy("public", jsonrpc.MethodPolicy{Public: true}),
+	)
+
+	cases := []struct {
+		method string
+		header string
+		value  string
+		expect string
+	}{
+		{"read", "", "", fmt.Sprintf(`"error":{"code":%d,"message":"Unauthorized"}`, jsonrpc.UnauthorizedError)},
+		{"read", "Authorization", "Bearer invalid", fmt.Sprintf(`"error":{"code":%d,"message":"Unauthorized"}`, jsonrpc.UnauthorizedError)},
+		{"read", "Authorization", "Bearer reader", `"result":"bob"`},
+		{"read", "X-Api-Key", "123", `"result":"key:123"`},
+		{"write", "Authorization", "Bearer reader", fmt.Sprintf(`"error":{"code":%d,"message":"Forbidden"}`, jsonrpc.ForbiddenError)},
+		{"write", "Authorization", "bearer admin", `"result":"alice"`},
+		{"admin", "Authorization", "Bearer reader", fmt.Sprintf(`"error":{"code":%d,"message":"Forbidden"}`, jsonrpc.ForbiddenError)},
+		{"admin", "Authorization", "Bearer admin", `"result":"alice"`},
+		{"public", "", "", `"result":null`},
+		{"public", "Authorization", "Bearer reader", `"result":"bob"`},
+	}
+
+	for _, c := range cases {
+		decoded = false
+
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, c.method)))
+		if c.header != "" {
+			r.Header.Set(c.header, c.value)
+		}
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+
+		if !strings.Contains(rw.Body.String(), c.expect) {
+			t.Errorf("TC(%s %s) Expected %s, got %s", c.method, c.value, c.expect, rw.Body.String())
+		}
+		if strings.Contains(c.expect, "error") && decoded {
+			t.Errorf("TC(%s %s) Expected rejected call not to reach the handler", c.method, c.value)
+		}
+	}
+}
+
+func TestServerPolicyWithoutAuthenticator(t *testing.T) {
+	for _, option := range []jsonrpc.ServerOption{
+		jsonrpc.ServerDefaultPolicy(jsonrpc.MethodPolicy{Scopes: []string{"read"}}),
+		jsonrpc.ServerMethodPolicy(testMethodName, jsonrpc.MethodPolicy{Roles: []string{"admin"}}),
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic of policy without authenticator")
+				}
+			}()
+			jsonrpc.NewServer(jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)}, option)
+		}()
+	}
+}
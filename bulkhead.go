@@ -0,0 +1,155 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Bulkhead limits the number of calls executed concurrently.
+type Bulkhead struct {
+	// Limit is the maximum number of calls executed concurrently. It must be
+	// positive, the options panic otherwise.
+	Limit int
+
+	// Wait is the maximum time a call waits in the queue for a free slot
+	// before it is rejected with ServerBusyError. Zero rejects the call
+	// immediately. The wait is cut short when the call context is done.
+	Wait time.Duration
+
+	// RetryAfter is suggested to rejected clients in RetryAfterData. By
+	// default, one second.
+	RetryAfter time.Duration
+}
+
+// BulkheadStats are counters of a Bulkhead
+type BulkheadStats struct {
+	InFlight int64
+	Waiting  int64
+	Rejected int64
+}
+
+// ServerStats are counters exposed by the Server for monitoring
+type ServerStats struct {
+	// Global counts the calls limited by ServerMaxInFlight
+	Global BulkheadStats
+
+	// Methods counts the calls limited by ServerMethodMaxInFlight
+	Methods map[string]BulkheadStats
+}
+
+// ServerMaxInFlight limits the number of calls executed concurrently by the
+// Server across all methods.
+func ServerMaxInFlight(b Bulkhead) ServerOption {
+	return func(s *Server) { s.bulkhead = newBulkhead(b) }
+}
+
+// ServerMethodMaxInFlight limits the number of calls of the method executed
+// concurrently.
+func ServerMethodMaxInFlight(method string, b Bulkhead) ServerOption {
+	return func(s *Server) {
+		if s.methodBulkheads == nil {
+			s.methodBulkheads = map[string]*bulkhead{}
+		}
+		s.methodBulkheads[method] = newBulkhead(b)
+	}
+}
+
+// Stats returns the counters of the bulkheads
+func (s *Server) Stats() ServerStats {
+	stats := ServerStats{
+		Methods: make(map[string]BulkheadStats, len(s.methodBulkheads)),
+	}
+	if s.bulkhead != nil {
+		stats.Global = s.bulkhead.stats()
+	}
+	for method, b := range s.methodBulkheads {
+		stats.Methods[method] = b.stats()
+	}
+	return stats
+}
+
+// bulkheadMiddleware admits the call through the global bulkhead and the
+// bulkhead of the method. It runs inside the timeoutMiddleware, so the slot is
+// held until the handler returns, even after the call timed out, and the wait
+// for a free slot counts towards the timeout.
+func (s *Server) bulkheadMiddleware(next Handlerer) Handlerer {
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		method, _ := MethodFromContext(ctx)
+		for _, b := range []*bulkhead{s.bulkhead, s.methodBulkheads[method]} {
+			if b == nil {
+				continue
+			}
+			if !b.acquire(ctx) {
+				return nil, nil, withRetryAfter(NewServerBusyError(b.retryAfter))
+			}
+			defer b.release()
+		}
+		return next.ServeJSONRPC(ctx, requestHeader, params)
+	})
+}
+
+type bulkhead struct {
+	sem        chan struct{}
+	wait       time.Duration
+	retryAfter time.Duration
+
+	waiting  int64
+	rejected int64
+}
+
+func newBulkhead(b Bulkhead) *bulkhead {
+	if b.Limit <= 0 {
+		panic(fmt.Sprintf("Bulkhead limit must be positive, got %d", b.Limit))
+	}
+	retryAfter := b.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	return &bulkhead{
+		sem:        make(chan struct{}, b.Limit),
+		wait:       b.Wait,
+		retryAfter: retryAfter,
+	}
+}
+
+func (b *bulkhead) acquire(ctx context.Context) bool {
+	select {
+	case b.sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if b.wait > 0 {
+		atomic.AddInt64(&b.waiting, 1)
+		defer atomic.AddInt64(&b.waiting, -1)
+
+		timer := time.NewTimer(b.wait)
+		defer timer.Stop()
+
+		select {
+		case b.sem <- struct{}{}:
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	atomic.AddInt64(&b.rejected, 1)
+	return false
+}
+
+func (b *bulkhead) release() {
+	<-b.sem
+}
+
+func (b *bulkhead) stats() BulkheadStats {
+	return BulkheadStats{
+		InFlight: int64(len(b.sem)),
+		Waiting:  atomic.LoadInt64(&b.waiting),
+		Rejected: atomic.LoadInt64(&b.rejected),
+	}
+}
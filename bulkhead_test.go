@@ -0,0 +1,134 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServerMethodMaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			<-release
+			return json.RawMessage(`true`), nil, nil
+		})},
+		jsonrpc.ServerMethodMaxInFlight(testMethodName, jsonrpc.Bulkhead{Limit: 1, RetryAfter: 3 * time.Second}),
+	)
+
+	done := make(chan string)
+	go func() { done <- serveTestMethod(server, nil, false).Body.String() }()
+
+	waitFor(t, func() bool { return server.Stats().Methods[testMethodName].InFlight == 1 })
+
+	rw := serveTestMethod(server, nil, false)
+	expect := fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":%d,"message":"Server is busy","data":{"retryAfter":3}},"id":1}`, jsonrpc.ServerBusyError)
+	if got := strings.TrimSpace(rw.Body.String()); got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+
+	close(release)
+	if got, expect := strings.TrimSpace(<-done), `{"jsonrpc":"2.0","result":true,"id":1}`; got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+
+	stats := server.Stats().Methods[testMethodName]
+	if stats.InFlight != 0 || stats.Rejected != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestServerMaxInFlightQueue(t *testing.T) {
+	release := make(chan struct{})
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			<-release
+			return json.RawMessage(`true`), nil, nil
+		})},
+		jsonrpc.ServerMaxInFlight(jsonrpc.Bulkhead{Limit: 1, Wait: time.Minute}),
+	)
+
+	done := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- serveTestMethod(server, nil, false).Body.String() }()
+	}
+
+	waitFor(t, func() bool {
+		stats := server.Stats().Global
+		return stats.InFlight == 1 && stats.Waiting == 1
+	})
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if got, expect := strings.TrimSpace(<-done), `{"jsonrpc":"2.0","result":true,"id":1}`; got != expect {
+			t.Errorf("Expected body '%s', got '%s'", expect, got)
+		}
+	}
+
+	if stats := server.Stats().Global; stats.Rejected != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestServerMaxInFlightTimeout(t *testing.T) {
+	release := make(chan struct{})
+	returned := make(chan struct{}, 1)
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			defer func() { returned <- struct{}{} }()
+			<-release
+			return json.RawMessage(`true`), nil, nil
+		})},
+		jsonrpc.ServerMaxInFlight(jsonrpc.Bulkhead{Limit: 1}),
+		jsonrpc.ServerTimeout(10*time.Millisecond),
+	)
+
+	rw := serveTestMethod(server, nil, false)
+	if got, expect := strings.TrimSpace(rw.Body.String()), fmt.Sprintf(`"code":%d`, jsonrpc.TimeoutError); !strings.Contains(got, expect) {
+		t.Fatalf("Expected body with %s, got '%s'", expect, got)
+	}
+	if got := server.Stats().Global.InFlight; got != 1 {
+		t.Errorf("Expected the slot to be held by the running handler, got %d in flight", got)
+	}
+
+	rw = serveTestMethod(server, nil, false)
+	if got, expect := strings.TrimSpace(rw.Body.String()), fmt.Sprintf(`"code":%d`, jsonrpc.ServerBusyError); !strings.Contains(got, expect) {
+		t.Errorf("Expected body with %s, got '%s'", expect, got)
+	}
+
+	close(release)
+	<-returned
+	waitFor(t, func() bool { return server.Stats().Global.InFlight == 0 })
+}
+
+func TestServerMaxInFlightInvalidLimit(t *testing.T) {
+	for _, option := range []jsonrpc.ServerOption{
+		jsonrpc.ServerMaxInFlight(jsonrpc.Bulkhead{}),
+		jsonrpc.ServerMethodMaxInFlight(testMethodName, jsonrpc.Bulkhead{Limit: -1}),
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic of non-positive limit")
+				}
+			}()
+			jsonrpc.NewServer(jsonrpc.Handlers{}, option)
+		}()
+	}
+}
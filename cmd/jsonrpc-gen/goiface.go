@@ -0,0 +1,326 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// loadInterface reads the api from the Go interface declared in the package
+// in the directory. Every method of the interface is a JSON-RPC method:
+//
+//	type Calculator interface {
+//		// Add adds two numbers.
+//		//jsonrpc:method calc.add
+//		//jsonrpc:error 100 Overflow
+//		Add(ctx context.Context, req AddRequest) (AddResponse, error)
+//	}
+//
+// The optional context is followed by a single struct sent by-name or by the
+// params sent by-position. The method returns the result and error, or only
+// error if the result is discarded. The method name is the name of the Go
+// method with the lowercase first letter unless set by jsonrpc:method.
+func loadInterface(dir, name string) (*api, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ifaceLoader{
+		api:     newAPI(name),
+		specs:   map[string]*ast.TypeSpec{},
+		docs:    map[string]*ast.CommentGroup{},
+		imports: map[string]string{},
+		names:   map[string]string{},
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			l.collect(f)
+		}
+	}
+
+	spec, ok := l.specs[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", name, dir)
+	}
+	iface, ok := spec.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not an interface", name)
+	}
+
+	for _, m := range iface.Methods.List {
+		fn, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported", fset.Position(m.Pos()))
+		}
+		am, err := l.method(m.Names[0].Name, fn, m.Doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fset.Position(m.Pos()), err)
+		}
+		l.api.Methods = append(l.api.Methods, am)
+	}
+
+	l.api.sort()
+	return l.api, nil
+}
+
+type ifaceLoader struct {
+	api     *api
+	specs   map[string]*ast.TypeSpec
+	docs    map[string]*ast.CommentGroup
+	imports map[string]string
+
+	// names are the names of the loaded types in the api by their names
+	// in the package
+	names map[string]string
+}
+
+// collect collects the type declarations and the imports of the file
+func (l *ifaceLoader) collect(f *ast.File) {
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		l.imports[name] = path
+	}
+
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gd.Specs {
+			ts := s.(*ast.TypeSpec)
+			l.specs[ts.Name.Name] = ts
+			l.docs[ts.Name.Name] = ts.Doc
+			if ts.Doc == nil && len(gd.Specs) == 1 {
+				l.docs[ts.Name.Name] = gd.Doc
+			}
+		}
+	}
+}
+
+func (l *ifaceLoader) method(name string, fn *ast.FuncType, doc *ast.CommentGroup) (*method, error) {
+	m := &method{
+		Name:    uniqueName(name, l.api.methodNames),
+		RPCName: strings.ToLower(name[:1]) + name[1:],
+	}
+
+	// directives are not part of the text of the comment
+	m.Description = strings.TrimSpace(doc.Text())
+	m.Deprecated = strings.Contains(m.Description, "Deprecated:")
+	if doc != nil {
+		for _, c := range doc.List {
+			text := strings.TrimPrefix(c.Text, "//")
+			switch {
+			case strings.HasPrefix(text, "jsonrpc:method "):
+				m.RPCName = strings.TrimSpace(strings.TrimPrefix(text, "jsonrpc:method "))
+			case strings.HasPrefix(text, "jsonrpc:error "):
+				parts := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(text, "jsonrpc:error ")), " ", 2)
+				code, err := strconv.Atoi(parts[0])
+				if err != nil || len(parts) != 2 {
+					return nil, fmt.Errorf("invalid directive %q, expected jsonrpc:error CODE MESSAGE", text)
+				}
+				l.api.addError(code, parts[1], "")
+				m.Errors = append(m.Errors, code)
+			}
+		}
+	}
+
+	params := flatten(fn.Params)
+	if len(params) > 0 && l.isSelector(params[0].Type, "context", "Context") {
+		params = params[1:]
+	}
+
+	if len(params) == 1 && l.isStruct(params[0].Type) {
+		m.Params = l.typeRef(params[0].Type)
+	} else {
+		for i, p := range params {
+			pname := "param" + strconv.Itoa(i)
+			if len(p.Names) > 0 && p.Names[0].Name != "_" {
+				pname = p.Names[0].Name
+			}
+			m.Positional = append(m.Positional, &param{Name: pname, Type: l.typeRef(p.Type)})
+		}
+	}
+
+	results := flatten(fn.Results)
+	if len(results) == 0 || len(results) > 2 {
+		return nil, fmt.Errorf("method %s has to return error or result and error", name)
+	}
+	if ident, ok := results[len(results)-1].Type.(*ast.Ident); !ok || ident.Name != "error" {
+		return nil, fmt.Errorf("method %s has to return error as the last result", name)
+	}
+	if len(results) == 2 {
+		m.Result = l.typeRef(results[0].Type)
+	}
+
+	return m, nil
+}
+
+// flatten returns the fields with at most one name each
+func flatten(fl *ast.FieldList) []*ast.Field {
+	if fl == nil {
+		return nil
+	}
+	var fields []*ast.Field
+	for _, f := range fl.List {
+		if len(f.Names) == 0 {
+			fields = append(fields, f)
+			continue
+		}
+		for _, n := range f.Names {
+			fields = append(fields, &ast.Field{Names: []*ast.Ident{n}, Type: f.Type})
+		}
+	}
+	return fields
+}
+
+// isSelector reports whether the expression is the type from the package
+func (l *ifaceLoader) isSelector(expr ast.Expr, path, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && l.imports[pkg.Name] == path && sel.Sel.Name == name
+}
+
+// isStruct reports whether the expression is a struct declared in the package
+func (l *ifaceLoader) isStruct(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	spec, ok := l.specs[ident.Name]
+	if !ok {
+		return false
+	}
+	_, ok = spec.Type.(*ast.StructType)
+	return ok
+}
+
+func (l *ifaceLoader) typeRef(expr ast.Expr) *typeRef {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return l.typeRef(t.X)
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return &typeRef{Kind: kindBool}
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			return &typeRef{Kind: kindInt}
+		case "float32", "float64":
+			return &typeRef{Kind: kindFloat}
+		case "string":
+			return &typeRef{Kind: kindString}
+		}
+		if _, ok := l.specs[t.Name]; ok {
+			l.load(t.Name)
+			return &typeRef{Kind: kindNamed, Name: l.names[t.Name]}
+		}
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (ident.Name == "byte" || ident.Name == "uint8") {
+			return &typeRef{Kind: kindBytes}
+		}
+		return &typeRef{Kind: kindArray, Elem: l.typeRef(t.Elt)}
+	case *ast.MapType:
+		return &typeRef{Kind: kindMap, Elem: l.typeRef(t.Value)}
+	case *ast.SelectorExpr:
+		if l.isSelector(t, "time", "Time") {
+			return &typeRef{Kind: kindTime}
+		}
+	}
+	return &typeRef{Kind: kindAny}
+}
+
+// load adds the named type declared in the package to the api
+func (l *ifaceLoader) load(name string) {
+	if _, ok := l.names[name]; ok {
+		return
+	}
+	l.names[name] = uniqueName(exportedName(name), l.api.names)
+
+	spec := l.specs[name]
+	t := &namedType{Name: l.names[name], Description: strings.TrimSpace(l.docs[name].Text())}
+	l.api.Types = append(l.api.Types, t)
+
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		t.Alias = l.typeRef(spec.Type)
+		return
+	}
+	t.Fields = l.fields(st)
+	uniqueFields(t.Fields)
+}
+
+// fields returns the fields of the struct encoded by encoding/json
+func (l *ifaceLoader) fields(st *ast.StructType) []*field {
+	var fields []*field
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(s)
+		}
+		jsonTag := tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		jsonName, opts := jsonTag, ""
+		if i := strings.Index(jsonTag, ","); i != -1 {
+			jsonName, opts = jsonTag[:i], jsonTag[i:]
+		}
+
+		_, pointer := f.Type.(*ast.StarExpr)
+		optional := pointer || strings.Contains(opts, ",omitempty")
+
+		if len(f.Names) == 0 {
+			// embedded struct declared in the package is promoted
+			if jsonName == "" && l.isStruct(f.Type) {
+				expr := f.Type
+				if star, ok := expr.(*ast.StarExpr); ok {
+					expr = star.X
+				}
+				embedded := l.specs[expr.(*ast.Ident).Name].Type.(*ast.StructType)
+				fields = append(fields, l.fields(embedded)...)
+			}
+			continue
+		}
+
+		description := tag.Get("description")
+		if description == "" {
+			description = strings.TrimSpace(f.Doc.Text())
+		}
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			name := jsonName
+			if name == "" {
+				name = n.Name
+			}
+			fields = append(fields, &field{
+				Name:        n.Name,
+				JSONName:    name,
+				Description: description,
+				Type:        l.typeRef(f.Type),
+				Optional:    optional,
+			})
+		}
+	}
+	return fields
+}
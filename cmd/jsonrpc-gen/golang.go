@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+)
+
+// generateGo generates the Go client package of the api
+func generateGo(a *api, pkg string) ([]byte, error) {
+	g := &goGenerator{api: a}
+	g.header(pkg)
+	g.errors()
+	g.types()
+	g.client()
+	g.batch()
+	g.buf.WriteString(goRuntime)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s\n%s", err, g.buf.Bytes())
+	}
+	return src, nil
+}
+
+type goGenerator struct {
+	api *api
+	buf bytes.Buffer
+}
+
+func (g *goGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// comment prints the text as a comment, the first line prefixed by the
+// name unless the text starts with it
+func (g *goGenerator) comment(indent, name, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if name != "" && !strings.HasPrefix(text, name+" ") {
+		text = name + " " + strings.ToLower(text[:1]) + text[1:]
+	}
+	for _, line := range strings.Split(text, "\n") {
+		g.printf("%s// %s\n", indent, line)
+	}
+}
+
+func (g *goGenerator) header(pkg string) {
+	g.printf("// Code generated by jsonrpc-gen. DO NOT EDIT.\n\n")
+	g.printf("// Package %s is the JSON-RPC client of %s.\n", pkg, g.api.Title)
+	g.printf("package %s\n\n", pkg)
+	g.printf("import (\n")
+	for _, imp := range []string{"bytes", "context", "encoding/json", "errors", "fmt", "io/ioutil", "net/http", "sync/atomic"} {
+		g.printf("\t%q\n", imp)
+	}
+	if g.usesTime() {
+		g.printf("\t\"time\"\n")
+	}
+	g.printf("\n\tjsonrpc \"github.com/fredipevcin/gokit-jsonrpc\"\n)\n\n")
+}
+
+func (g *goGenerator) usesTime() bool {
+	var uses func(t *typeRef) bool
+	uses = func(t *typeRef) bool {
+		return t != nil && (t.Kind == kindTime || uses(t.Elem))
+	}
+	for _, t := range g.api.Types {
+		if uses(t.Alias) {
+			return true
+		}
+		for _, f := range t.Fields {
+			if uses(f.Type) {
+				return true
+			}
+		}
+	}
+	for _, m := range g.api.Methods {
+		if uses(m.Params) || uses(m.Result) {
+			return true
+		}
+		for _, p := range m.Positional {
+			if uses(p.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *goGenerator) errors() {
+	if len(g.api.Errors) == 0 {
+		return
+	}
+	g.printf("// Error codes returned by the methods\nconst (\n")
+	for _, e := range g.api.Errors {
+		g.printf("\t// %s is the code of %q\n", e.Name, e.Message)
+		if e.Description != "" {
+			g.printf("\t// %s\n", strings.Replace(e.Description, "\n", "\n\t// ", -1))
+		}
+		g.printf("\t%s = %d\n", e.Name, e.Code)
+	}
+	g.printf(")\n\n")
+}
+
+func (g *goGenerator) types() {
+	for _, t := range g.api.Types {
+		name := exportedName(t.Name)
+		text := t.Description
+		if m := g.api.paramsOf(t.Name); text == "" && m != nil {
+			text = "are the params of " + m.RPCName
+		}
+		g.comment("", name, text)
+		if t.Alias != nil {
+			g.printf("type %s %s\n\n", name, g.typeName(t.Alias))
+			continue
+		}
+
+		g.printf("type %s struct {\n", name)
+		for _, f := range t.Fields {
+			g.comment("\t", "", f.Description)
+			typ := g.typeName(f.Type)
+			tag := f.JSONName
+			if f.Optional {
+				tag += ",omitempty"
+				if f.Type.Kind == kindNamed || f.Type.Kind == kindTime {
+					typ = "*" + typ
+				}
+			}
+			g.printf("\t%s %s `json:%q`\n", exportedName(f.Name), typ, tag)
+		}
+		g.printf("}\n\n")
+	}
+}
+
+func (g *goGenerator) typeName(t *typeRef) string {
+	switch t.Kind {
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "int64"
+	case kindFloat:
+		return "float64"
+	case kindString:
+		return "string"
+	case kindTime:
+		return "time.Time"
+	case kindBytes:
+		return "[]byte"
+	case kindArray:
+		return "[]" + g.typeName(t.Elem)
+	case kindMap:
+		return "map[string]" + g.typeName(t.Elem)
+	case kindNamed:
+		return exportedName(t.Name)
+	}
+	return "json.RawMessage"
+}
+
+// signature returns the params of the method in the client and the
+// expression of the params sent to the server
+func (g *goGenerator) signature(m *method) (params []string, value string) {
+	switch {
+	case m.Params != nil:
+		return []string{"params " + g.typeName(m.Params)}, "params"
+	case len(m.Positional) > 0:
+		var names []string
+		taken := map[string]bool{}
+		for _, p := range m.Positional {
+			name := uniqueName(goIdent(p.Name), taken)
+			params = append(params, name+" "+g.typeName(p.Type))
+			names = append(names, name)
+		}
+		return params, "[]interface{}{" + strings.Join(names, ", ") + "}"
+	}
+	return nil, "nil"
+}
+
+func (g *goGenerator) methodComment(m *method) {
+	text := m.Description
+	if text == "" {
+		text = m.Summary
+	}
+	if text == "" {
+		text = "calls " + m.RPCName + "."
+	}
+	g.comment("", m.Name, text)
+	if m.Deprecated && !strings.Contains(text, "Deprecated:") {
+		g.printf("//\n// Deprecated: the method is deprecated.\n")
+	}
+}
+
+func (g *goGenerator) client() {
+	title := g.api.Title
+	g.printf("// Client calls the methods of %s\n", title)
+	g.printf("type Client struct {\n\ttransport Transport\n\tlastID int64\n}\n\n")
+	g.printf("// NewClient constructs the Client sending the requests by the transport\n")
+	g.printf("func NewClient(transport Transport) *Client {\n\treturn &Client{transport: transport}\n}\n\n")
+	g.printf("// NewHTTPClient constructs the Client sending the requests to the URL\n")
+	g.printf("func NewHTTPClient(url string) *Client {\n\treturn NewClient(&HTTPTransport{URL: url})\n}\n\n")
+
+	for _, m := range g.api.Methods {
+		params, value := g.signature(m)
+		args := append([]string{"ctx context.Context"}, params...)
+
+		g.methodComment(m)
+		if m.Result == nil {
+			g.printf("func (c *Client) %s(%s) error {\n", m.Name, strings.Join(args, ", "))
+			g.printf("\treturn c.call(ctx, %q, %s, nil)\n}\n\n", m.RPCName, value)
+			continue
+		}
+
+		result := g.typeName(m.Result)
+		g.printf("func (c *Client) %s(%s) (%s, error) {\n", m.Name, strings.Join(args, ", "), result)
+		g.printf("\tvar result %s\n", result)
+		g.printf("\terr := c.call(ctx, %q, %s, &result)\n", m.RPCName, value)
+		g.printf("\treturn result, err\n}\n\n")
+	}
+}
+
+func (g *goGenerator) batch() {
+	for _, m := range g.api.Methods {
+		params, value := g.signature(m)
+		resultValue := "nil"
+		if m.Result != nil {
+			params = append(params, "result *"+g.typeName(m.Result))
+			resultValue = "result"
+		}
+
+		g.printf("// %s adds the call of %s to the batch, the result is set once the batch is sent.\n", m.Name, m.RPCName)
+		g.printf("func (b *Batch) %s(%s) *BatchCall {\n", m.Name, strings.Join(params, ", "))
+		g.printf("\treturn b.add(%q, %s, %s)\n}\n\n", m.RPCName, value, resultValue)
+	}
+}
+
+// goReserved are the identifiers used in the bodies of the generated methods
+var goReserved = map[string]bool{
+	"b": true, "c": true, "ctx": true, "err": true, "json": true, "nil": true,
+	"params": true, "result": true, "time": true,
+}
+
+// goIdent returns the name as a valid Go identifier
+func goIdent(name string) string {
+	ident := unexportedName(name)
+	if token.IsKeyword(ident) || goReserved[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+const goRuntime = `// Transport sends the encoded request and returns the encoded response
+type Transport interface {
+	RoundTrip(ctx context.Context, request []byte) ([]byte, error)
+}
+
+// HTTPTransport sends the requests by HTTP POST to the URL
+type HTTPTransport struct {
+	URL string
+
+	// Client sends the requests, http.DefaultClient if nil
+	Client *http.Client
+
+	// Header is sent with every request
+	Header http.Header
+}
+
+// RoundTrip implements Transport
+func (t *HTTPTransport) RoundTrip(ctx context.Context, request []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, t.URL, bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range t.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", jsonrpc.ContentType)
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("jsonrpc: unexpected HTTP status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+type request struct {
+	JSONRPC string      ` + "`json:\"jsonrpc\"`" + `
+	Method  string      ` + "`json:\"method\"`" + `
+	Params  interface{} ` + "`json:\"params,omitempty\"`" + `
+	ID      int64       ` + "`json:\"id\"`" + `
+}
+
+type response struct {
+	Result json.RawMessage ` + "`json:\"result\"`" + `
+	Error  *jsonrpc.Error  ` + "`json:\"error\"`" + `
+	ID     int64           ` + "`json:\"id\"`" + `
+}
+
+// decode returns the error of the response or decodes the result
+func (r response) decode(result interface{}) error {
+	if r.Error != nil {
+		return *r.Error
+	}
+	if result == nil || len(r.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+func (c *Client) newRequest(method string, params interface{}) request {
+	return request{
+		JSONRPC: jsonrpc.Version,
+		Method:  method,
+		Params:  params,
+		ID:      atomic.AddInt64(&c.lastID, 1),
+	}
+}
+
+// call calls the method, errors returned by the server are jsonrpc.Error
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	b, err := json.Marshal(c.newRequest(method, params))
+	if err != nil {
+		return err
+	}
+	b, err = c.transport.RoundTrip(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	var resp response
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return fmt.Errorf("jsonrpc: invalid response: %s", err)
+	}
+	return resp.decode(result)
+}
+
+// Batch collects the calls sent in a single request
+type Batch struct {
+	client   *Client
+	requests []request
+	calls    map[int64]*BatchCall
+}
+
+// BatchCall is a call in the Batch
+type BatchCall struct {
+	// Err is the error of the call, set once the batch is sent
+	Err error
+
+	result interface{}
+}
+
+// NewBatch returns an empty batch
+func (c *Client) NewBatch() *Batch {
+	return &Batch{client: c, calls: map[int64]*BatchCall{}}
+}
+
+func (b *Batch) add(method string, params, result interface{}) *BatchCall {
+	req := b.client.newRequest(method, params)
+	call := &BatchCall{result: result}
+	b.requests = append(b.requests, req)
+	b.calls[req.ID] = call
+	return call
+}
+
+// Send sends the calls of the batch. The error is returned if the batch could
+// not be sent, errors of the calls are set to BatchCall.Err.
+func (b *Batch) Send(ctx context.Context) error {
+	if len(b.requests) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(b.requests)
+	if err != nil {
+		return err
+	}
+	body, err = b.client.transport.RoundTrip(ctx, body)
+	if err != nil {
+		return err
+	}
+
+	var responses []response
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// the server responds with a single error if the batch is invalid
+		var resp response
+		if json.Unmarshal(body, &resp) == nil && resp.Error != nil {
+			return *resp.Error
+		}
+		return fmt.Errorf("jsonrpc: invalid response: %s", err)
+	}
+
+	for _, resp := range responses {
+		if call, ok := b.calls[resp.ID]; ok {
+			call.Err = resp.decode(call.result)
+			delete(b.calls, resp.ID)
+		}
+	}
+	for _, call := range b.calls {
+		call.Err = errors.New("jsonrpc: no response to the call")
+	}
+	return nil
+}
+`
@@ -0,0 +1,101 @@
+// Command jsonrpc-gen generates typed JSON-RPC clients from a Go interface, an
+// OpenRPC document or a running server supporting rpc.discover.
+//
+// Usage:
+//
+//	jsonrpc-gen -openrpc FILE [-lang go|ts] [-pkg NAME] [-o FILE]
+//	jsonrpc-gen -discover URL [-lang go|ts] [-pkg NAME] [-o FILE]
+//	jsonrpc-gen -interface NAME [-dir DIR] [-lang go|ts] [-pkg NAME] [-o FILE]
+//
+// The generated Go package has one method per JSON-RPC method, the params and
+// result types, the error code constants, batch helpers and HTTP transport.
+//
+// The generated TypeScript module has the type definitions, the error types
+// discriminated by the code and the fetch based client with batch support.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+type options struct {
+	openrpc  string
+	discover string
+	iface    string
+	dir      string
+	lang     string
+	pkg      string
+	output   string
+}
+
+func main() {
+	var o options
+	flag.StringVar(&o.openrpc, "openrpc", "", "OpenRPC document the client is generated from")
+	flag.StringVar(&o.discover, "discover", "", "URL of the server the OpenRPC document is discovered from by rpc.discover")
+	flag.StringVar(&o.iface, "interface", "", "Go interface the client is generated from")
+	flag.StringVar(&o.dir, "dir", ".", "directory of the Go package declaring the interface")
+	flag.StringVar(&o.lang, "lang", "go", "language of the generated client, go or ts")
+	flag.StringVar(&o.pkg, "pkg", "client", "package name of the generated Go client")
+	flag.StringVar(&o.output, "o", "", "output file, stdout by default")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %[1]s -openrpc FILE [flags]\n  %[1]s -discover URL [flags]\n  %[1]s -interface NAME [flags]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	inputs := 0
+	for _, in := range []string{o.openrpc, o.discover, o.iface} {
+		if in != "" {
+			inputs++
+		}
+	}
+	if inputs != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(o); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(o options) error {
+	var (
+		a   *api
+		err error
+	)
+	switch {
+	case o.openrpc != "":
+		a, err = loadOpenRPC(o.openrpc)
+	case o.discover != "":
+		a, err = discover(o.discover)
+	default:
+		a, err = loadInterface(o.dir, o.iface)
+	}
+	if err != nil {
+		return err
+	}
+
+	var src []byte
+	switch o.lang {
+	case "go":
+		src, err = generateGo(a, o.pkg)
+	case "ts":
+		src = generateTypeScript(a)
+	default:
+		err = fmt.Errorf("unknown language %q", o.lang)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(o.output, src, 0644)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expect, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expect) {
+		t.Errorf("Generated code differs from %s, run go test -update to update it\n%s", path, got)
+	}
+}
+
+func TestGenerateGoFromInterface(t *testing.T) {
+	a, err := loadInterface(filepath.Join("testdata", "calc"), "Calculator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generateGo(a, "calc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "calc_interface.go.golden", src)
+	typeCheck(t, src)
+}
+
+func TestGenerateGoFromOpenRPC(t *testing.T) {
+	a, err := loadOpenRPC(filepath.Join("testdata", "calc.openrpc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generateGo(a, "calc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "calc_openrpc.go.golden", src)
+	typeCheck(t, src)
+}
+
+func TestLoadInterfaceErrors(t *testing.T) {
+	for _, name := range []string{"Missing", "AddRequest"} {
+		if _, err := loadInterface(filepath.Join("testdata", "calc"), name); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"calc.add":     "CalcAdd",
+		"Not allowed":  "NotAllowed",
+		"user_id":      "UserId",
+		"2fa":          "X2fa",
+		"":             "X",
+		"already.Done": "AlreadyDone",
+	}
+	for name, expect := range tests {
+		if got := exportedName(name); got != expect {
+			t.Errorf("%q: expected %s, got %s", name, expect, got)
+		}
+	}
+}
+
+func TestGenerateGoCollidingNames(t *testing.T) {
+	a, err := loadOpenRPC(filepath.Join("testdata", "collisions.openrpc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var methods, types, errors []string
+	for _, m := range a.Methods {
+		methods = append(methods, m.Name)
+	}
+	for _, typ := range a.Types {
+		types = append(types, typ.Name)
+	}
+	for _, e := range a.Errors {
+		errors = append(errors, e.Name)
+	}
+	if got, expect := strings.Join(methods, " "), "NewBatch2 Send2 UserGet UserGet2"; got != expect {
+		t.Errorf("Expected methods %s, got %s", expect, got)
+	}
+	if got, expect := strings.Join(types, " "), "Batch2 Client2 Error2 NotFoundError2 Transport2 UserGet2Params UserGetParams"; got != expect {
+		t.Errorf("Expected types %s, got %s", expect, got)
+	}
+	if got, expect := strings.Join(errors, " "), "ErrorCodeNotFound2 ErrorCodeNotFound"; got != expect {
+		t.Errorf("Expected errors %s, got %s", expect, got)
+	}
+
+	src, err := generateGo(a, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "collisions.go.golden", src)
+	typeCheck(t, src)
+}
+
+// typeCheck fails the test if the generated code does not compile
+func typeCheck(t *testing.T, src []byte) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("client", fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("Generated code does not compile: %s", err)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// api is the description of the service the clients are generated from
+type api struct {
+	Title   string
+	Methods []*method
+	Types   []*namedType
+	Errors  []errorCode
+
+	// names are taken by the types and the errors, methodNames by the
+	// methods
+	names       map[string]bool
+	methodNames map[string]bool
+}
+
+// reservedNames are declared by the Go and the TypeScript runtime or used by
+// the TypeScript runtime as globals
+var reservedNames = []string{
+	"Batch", "BatchCall", "Client", "HTTPTransport", "NewClient", "NewHTTPClient", "Transport",
+	"BaseBatch", "BaseClient", "ClientOptions", "ErrorCode", "JSONRPCError", "Result",
+	"RpcErrorObject", "RpcRequest", "RpcResponse", "StandardError",
+	"Array", "Error", "Map", "Promise", "Record",
+}
+
+// reservedMethods are the methods of Client and Batch of the Go runtime
+var reservedMethods = []string{"NewBatch", "Send"}
+
+func newAPI(title string) *api {
+	a := &api{Title: title, names: map[string]bool{}, methodNames: map[string]bool{}}
+	for _, name := range reservedNames {
+		a.names[name] = true
+	}
+	for _, name := range reservedMethods {
+		a.methodNames[name] = true
+	}
+	return a
+}
+
+type method struct {
+	// Name is the exported name of the method in the client
+	Name        string
+	RPCName     string
+	Summary     string
+	Description string
+	Deprecated  bool
+
+	// Params is the type of the params sent by-name, nil if the method has
+	// positional or no params
+	Params *typeRef
+
+	// Positional are the params sent by-position
+	Positional []*param
+
+	// Result is the type of the result, nil if the result is discarded
+	Result *typeRef
+
+	// Errors are the codes of the errors declared by the method
+	Errors []int
+}
+
+type param struct {
+	Name        string
+	Description string
+	Type        *typeRef
+}
+
+type kind int
+
+const (
+	kindAny kind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindTime
+	kindBytes
+	kindArray
+	kindMap
+	kindNamed
+)
+
+// typeRef is a reference to the type of a value
+type typeRef struct {
+	Kind kind
+
+	// Elem is the element of arrays and maps
+	Elem *typeRef
+
+	// Name of the named type
+	Name string
+}
+
+// namedType is a struct if it has Fields, otherwise it is defined by Alias
+type namedType struct {
+	Name        string
+	Description string
+	Fields      []*field
+	Alias       *typeRef
+}
+
+type field struct {
+	Name        string
+	JSONName    string
+	Description string
+	Type        *typeRef
+	Optional    bool
+}
+
+type errorCode struct {
+	Name        string
+	Code        int
+	Message     string
+	Description string
+}
+
+// addError adds the error code unless it is already defined, the description
+// is set if the defined one has none. The error is named ErrorCodeName in Go
+// and NameError in TypeScript, both names are taken.
+func (a *api) addError(code int, message, description string) {
+	for i, e := range a.Errors {
+		if e.Code == code {
+			if e.Description == "" {
+				a.Errors[i].Description = description
+			}
+			return
+		}
+	}
+
+	base := exportedName(message)
+	name := base
+	for i := 2; a.names["ErrorCode"+name] || a.names[name+"Error"]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	a.names[name+"Error"] = true
+	a.Errors = append(a.Errors, errorCode{
+		Name:        uniqueName("ErrorCode"+name, a.names),
+		Code:        code,
+		Message:     message,
+		Description: description,
+	})
+}
+
+// paramsOf returns the method whose params sent by-name are the named type,
+// nil if there is none
+func (a *api) paramsOf(name string) *method {
+	for _, m := range a.Methods {
+		if m.Params != nil && m.Params.Kind == kindNamed && m.Params.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// sort orders the methods, types and errors for stable output
+func (a *api) sort() {
+	sort.Slice(a.Methods, func(i, j int) bool { return a.Methods[i].RPCName < a.Methods[j].RPCName })
+	sort.Slice(a.Types, func(i, j int) bool { return a.Types[i].Name < a.Types[j].Name })
+	sort.Slice(a.Errors, func(i, j int) bool { return a.Errors[i].Code > a.Errors[j].Code })
+}
+
+// uniqueFields names the fields by exported identifiers unique in the struct
+func uniqueFields(fields []*field) {
+	names := map[string]bool{}
+	for _, f := range fields {
+		f.Name = uniqueName(exportedName(f.Name), names)
+	}
+}
+
+// uniqueName returns the name, suffixed by a number if it is already taken,
+// and marks it as taken
+func uniqueName(name string, names map[string]bool) string {
+	for i, base := 2, name; names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	names[name] = true
+	return name
+}
+
+// exportedName converts the name to an exported identifier, e.g. "user.get"
+// is "UserGet" and "not found" is "NotFound".
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if b.Len() == 0 && unicode.IsDigit(r) {
+				b.WriteRune('X')
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+// unexportedName converts the name to an unexported identifier
+func unexportedName(name string) string {
+	exported := []rune(exportedName(name))
+	exported[0] = unicode.ToLower(exported[0])
+	return string(exported)
+}
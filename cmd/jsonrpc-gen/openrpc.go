@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+// loadOpenRPC reads the api from the OpenRPC document. Object schemas become
+// named types, schemas which cannot be expressed, e.g. anyOf or $ref, are any.
+func loadOpenRPC(path string) (*api, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc jsonrpc.OpenRPCDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return fromOpenRPC(doc), nil
+}
+
+// discover reads the api from the OpenRPC document returned by rpc.discover
+// of the server, see jsonrpc.ServerIntrospection.
+func discover(url string) (*api, error) {
+	body := strings.NewReader(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`)
+	resp, err := http.Post(url, jsonrpc.ContentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Result *jsonrpc.OpenRPCDocument `json:"result"`
+		Error  *jsonrpc.Error           `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("rpc.discover: invalid response: %s", err)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("rpc.discover: %d %s", res.Error.Code, res.Error.Message)
+	}
+	if res.Result == nil {
+		return nil, fmt.Errorf("rpc.discover: empty result")
+	}
+	return fromOpenRPC(*res.Result), nil
+}
+
+func fromOpenRPC(doc jsonrpc.OpenRPCDocument) *api {
+	a := newAPI(doc.Info.Title)
+
+	for _, m := range doc.Methods {
+		if jsonrpc.IsReservedMethod(m.Name) {
+			continue
+		}
+
+		name := uniqueName(exportedName(m.Name), a.methodNames)
+		am := &method{
+			Name:        name,
+			RPCName:     m.Name,
+			Summary:     m.Summary,
+			Description: m.Description,
+			Deprecated:  m.Deprecated,
+		}
+
+		if m.ParamStructure == "by-position" {
+			for i, p := range m.Params {
+				am.Positional = append(am.Positional, &param{
+					Name:        p.Name,
+					Description: p.Description,
+					Type:        a.schemaType(p.Schema, name+"Param"+strconv.Itoa(i)),
+				})
+			}
+		} else if len(m.Params) > 0 {
+			params := &jsonrpc.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonrpc.Schema{},
+			}
+			for _, p := range m.Params {
+				s := p.Schema
+				if s == nil {
+					s = &jsonrpc.Schema{}
+				}
+				if p.Description != "" && s.Description == "" {
+					copied := *s
+					copied.Description = p.Description
+					s = &copied
+				}
+				params.Properties[p.Name] = s
+				if p.Required {
+					params.Required = append(params.Required, p.Name)
+				}
+			}
+			am.Params = a.schemaType(params, name+"Params")
+		}
+
+		if m.Result != nil {
+			am.Result = a.schemaType(m.Result.Schema, name+"Result")
+		}
+
+		for _, e := range m.Errors {
+			a.addError(e.Code, e.Message, "")
+			am.Errors = append(am.Errors, e.Code)
+		}
+		a.Methods = append(a.Methods, am)
+	}
+
+	// registered error codes of the application, see jsonrpc.RegisterError
+	if doc.Components != nil {
+		for _, e := range doc.Components.Errors {
+			if !jsonrpc.IsReservedErrorCode(e.Code) {
+				a.addError(e.Code, e.Message, e.Description)
+			}
+		}
+	}
+
+	a.sort()
+	return a
+}
+
+// schemaType returns the type of the schema, object schemas are added to the
+// types of the api with the name or the title of the schema
+func (a *api) schemaType(s *jsonrpc.Schema, name string) *typeRef {
+	if s == nil {
+		return &typeRef{Kind: kindAny}
+	}
+
+	switch s.Type {
+	case "boolean":
+		return &typeRef{Kind: kindBool}
+	case "integer":
+		return &typeRef{Kind: kindInt}
+	case "number":
+		return &typeRef{Kind: kindFloat}
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return &typeRef{Kind: kindTime}
+		case "byte":
+			return &typeRef{Kind: kindBytes}
+		}
+		return &typeRef{Kind: kindString}
+	case "array":
+		return &typeRef{Kind: kindArray, Elem: a.schemaType(s.Items, name+"Item")}
+	case "object":
+		if len(s.Properties) == 0 {
+			return &typeRef{Kind: kindMap, Elem: a.schemaType(s.AdditionalProperties, name+"Value")}
+		}
+	default:
+		return &typeRef{Kind: kindAny}
+	}
+
+	if s.Title != "" {
+		name = exportedName(s.Title)
+	}
+	name = uniqueName(name, a.names)
+
+	t := &namedType{Name: name, Description: s.Description}
+	a.Types = append(a.Types, t)
+
+	props := make([]string, 0, len(s.Properties))
+	for p := range s.Properties {
+		props = append(props, p)
+	}
+	sort.Strings(props)
+	for _, p := range props {
+		ps := s.Properties[p]
+		t.Fields = append(t.Fields, &field{
+			Name:        exportedName(p),
+			JSONName:    p,
+			Description: ps.Description,
+			Type:        a.schemaType(ps, name+exportedName(p)),
+			Optional:    !contains(s.Required, p),
+		})
+	}
+	uniqueFields(t.Fields)
+
+	return &typeRef{Kind: kindNamed, Name: name}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
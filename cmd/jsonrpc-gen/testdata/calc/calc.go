@@ -0,0 +1,58 @@
+package calc
+
+import (
+	"context"
+	"time"
+)
+
+// Calculator is the service generated clients call
+type Calculator interface {
+	// Add adds two numbers.
+	//jsonrpc:method calc.add
+	//jsonrpc:error 100 Overflow
+	Add(ctx context.Context, req AddRequest) (AddResult, error)
+
+	// Concat joins the strings.
+	//
+	// Deprecated: use Join.
+	Concat(ctx context.Context, left, right string) (string, error)
+
+	// History returns the previous operations since the time.
+	History(ctx context.Context, since time.Time) ([]Operation, error)
+
+	// Reset clears the history.
+	//jsonrpc:error 101 Not allowed
+	Reset(ctx context.Context) error
+}
+
+type base struct {
+	ID string `json:"id"`
+}
+
+// AddRequest are the operands
+type AddRequest struct {
+	base
+	A int `json:"a" description:"first operand"`
+	// B is the second operand
+	B       int               `json:"b,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	ignored int
+}
+
+// AddResult is the sum
+type AddResult struct {
+	Sum       float64    `json:"sum"`
+	Operation *Operation `json:"operation,omitempty"`
+}
+
+// Operation is a calculation
+type Operation struct {
+	Kind Kind      `json:"kind"`
+	At   time.Time `json:"at"`
+	Data []byte    `json:"data"`
+	Any  interface{}
+	Skip string `json:"-"`
+}
+
+// Kind is the kind of the operation
+type Kind string
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+// standardErrors returns the codes of the errors returned by the server
+// itself, the registered codes in the reserved range
+func standardErrors() []int {
+	var codes []int
+	for _, d := range jsonrpc.RegisteredErrors() {
+		if jsonrpc.IsReservedErrorCode(d.Code) {
+			codes = append(codes, d.Code)
+		}
+	}
+	return codes
+}
+
+// generateTypeScript generates the TypeScript types and fetch based client of
+// the api
+func generateTypeScript(a *api) []byte {
+	g := &tsGenerator{api: a}
+	g.printf("// Code generated by jsonrpc-gen. DO NOT EDIT.\n\n")
+	g.printf("/* eslint-disable */\n\n")
+	g.types()
+	g.errors()
+	g.buf.WriteString(tsRuntime)
+	g.client()
+	g.batch()
+	return g.buf.Bytes()
+}
+
+type tsGenerator struct {
+	api *api
+	buf bytes.Buffer
+}
+
+func (g *tsGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// doc prints the JSDoc comment
+func (g *tsGenerator) doc(indent, text string, deprecated bool, tags ...string) {
+	text = strings.TrimSpace(text)
+	if deprecated && !strings.Contains(text, "Deprecated:") {
+		tags = append(tags, "@deprecated")
+	}
+	var lines []string
+	if text != "" {
+		lines = strings.Split(strings.Replace(text, "*/", "*\\/", -1), "\n")
+	}
+	if len(lines) > 0 && len(tags) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, tags...)
+
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		g.printf("%s/** %s */\n", indent, lines[0])
+		return
+	}
+	g.printf("%s/**\n", indent)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Deprecated:") {
+			line = "@deprecated" + strings.TrimPrefix(line, "Deprecated:")
+		}
+		g.printf("%s *%s\n", indent, strings.TrimRight(" "+line, " "))
+	}
+	g.printf("%s */\n", indent)
+}
+
+func (g *tsGenerator) types() {
+	for _, t := range g.api.Types {
+		text := t.Description
+		if m := g.api.paramsOf(t.Name); text == "" && m != nil {
+			text = "Params of " + m.RPCName + "."
+		}
+		g.doc("", text, false)
+		name := exportedName(t.Name)
+		if t.Alias != nil {
+			g.printf("export type %s = %s;\n\n", name, g.typeName(t.Alias))
+			continue
+		}
+
+		g.printf("export interface %s {\n", name)
+		for _, f := range t.Fields {
+			g.doc("  ", f.Description, false)
+			optional := ""
+			if f.Optional {
+				optional = "?"
+			}
+			g.printf("  %s%s: %s;\n", tsPropertyName(f.JSONName), optional, g.typeName(f.Type))
+		}
+		g.printf("}\n\n")
+	}
+}
+
+func (g *tsGenerator) typeName(t *typeRef) string {
+	switch t.Kind {
+	case kindBool:
+		return "boolean"
+	case kindInt, kindFloat:
+		return "number"
+	case kindString, kindTime, kindBytes:
+		return "string"
+	case kindArray:
+		return "Array<" + g.typeName(t.Elem) + ">"
+	case kindMap:
+		return "Record<string, " + g.typeName(t.Elem) + ">"
+	case kindNamed:
+		return exportedName(t.Name)
+	}
+	return "unknown"
+}
+
+// errorType returns the name of the error type of the declared error code
+func (g *tsGenerator) errorType(code int) string {
+	for _, e := range g.api.Errors {
+		if e.Code == code {
+			return strings.TrimPrefix(e.Name, "ErrorCode") + "Error"
+		}
+	}
+	return "RpcErrorObject"
+}
+
+func (g *tsGenerator) errors() {
+	g.printf("/** Error object of the JSON-RPC response, discriminated by the code. */\n")
+	g.printf("export interface RpcErrorObject<C extends number = number, D = unknown> {\n")
+	g.printf("  code: C;\n  message: string;\n  data?: D;\n}\n\n")
+
+	var codes []string
+	for _, code := range standardErrors() {
+		codes = append(codes, strconv.Itoa(code))
+	}
+	g.printf("/** Errors returned by the server for any method. */\n")
+	g.printf("export type StandardError = RpcErrorObject<%s>;\n\n", strings.Join(codes, " | "))
+
+	if len(g.api.Errors) == 0 {
+		g.printf("export const ErrorCode = {} as const;\n\n")
+		return
+	}
+
+	g.printf("/** Codes of the errors declared by the methods. */\n")
+	g.printf("export const ErrorCode = {\n")
+	for _, e := range g.api.Errors {
+		g.printf("  %s: %d,\n", strings.TrimPrefix(e.Name, "ErrorCode"), e.Code)
+	}
+	g.printf("} as const;\n\n")
+
+	for _, e := range g.api.Errors {
+		g.doc("", strings.TrimSpace(strconv.Quote(e.Message)+" error.\n\n"+e.Description), false)
+		g.printf("export type %s = RpcErrorObject<%d>;\n\n", g.errorType(e.Code), e.Code)
+	}
+}
+
+// methodError returns the union of the error types of the method
+func (g *tsGenerator) methodError(m *method) string {
+	types := []string{}
+	for _, code := range m.Errors {
+		types = append(types, g.errorType(code))
+	}
+	return strings.Join(append(types, "StandardError"), " | ")
+}
+
+// signature returns the params of the method and the expression of the
+// params sent to the server
+func (g *tsGenerator) signature(m *method) (params []string, value string) {
+	switch {
+	case m.Params != nil:
+		return []string{"params: " + g.typeName(m.Params)}, ", params"
+	case len(m.Positional) > 0:
+		var names []string
+		taken := map[string]bool{}
+		for _, p := range m.Positional {
+			name := uniqueName(tsIdent(p.Name), taken)
+			params = append(params, name+": "+g.typeName(p.Type))
+			names = append(names, name)
+		}
+		return params, ", [" + strings.Join(names, ", ") + "]"
+	}
+	return nil, ""
+}
+
+func (g *tsGenerator) result(m *method) string {
+	if m.Result == nil {
+		return "void"
+	}
+	return g.typeName(m.Result)
+}
+
+func (g *tsGenerator) client() {
+	g.printf("/** Client calls the methods of %s. */\n", g.api.Title)
+	g.printf("export class Client extends BaseClient {\n")
+	for _, m := range g.api.Methods {
+		params, value := g.signature(m)
+		text := m.Description
+		if text == "" {
+			text = m.Summary
+		}
+		g.doc("  ", text, m.Deprecated, "@throws {JSONRPCError<"+g.methodError(m)+">}")
+		g.printf("  %s(%s): Promise<%s> {\n", tsMethodName(m.Name, "batch"), strings.Join(params, ", "), g.result(m))
+		g.printf("    return this._call(%q%s);\n  }\n\n", m.RPCName, value)
+	}
+	g.printf("  /** batch returns a batch of calls sent in a single request. */\n")
+	g.printf("  batch(): Batch {\n    return new Batch(this);\n  }\n}\n\n")
+}
+
+func (g *tsGenerator) batch() {
+	g.printf("/** Batch collects the calls sent in a single request by send. */\n")
+	g.printf("export class Batch extends BaseBatch {\n")
+	for i, m := range g.api.Methods {
+		params, value := g.signature(m)
+		g.doc("  ", "Adds the call of "+m.RPCName+", the promise is resolved once the batch is sent.", m.Deprecated)
+		g.printf("  %s(%s): Promise<Result<%s, %s>> {\n", tsMethodName(m.Name, "send"), strings.Join(params, ", "), g.result(m), g.methodError(m))
+		g.printf("    return this._add(%q%s);\n  }\n", m.RPCName, value)
+		if i < len(g.api.Methods)-1 {
+			g.printf("\n")
+		}
+	}
+	g.printf("}\n")
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsPropertyName quotes the property name if it is not an identifier
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+var tsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true, "let": true, "static": true, "implements": true, "interface": true,
+	"package": true, "private": true, "protected": true, "public": true, "await": true,
+}
+
+// tsMethodName returns the name of the method in the generated class, the
+// names taken by the class get suffix "_". The members of the base classes
+// start with "_" so they never collide.
+func tsMethodName(name string, taken ...string) string {
+	method := unexportedName(name)
+	for _, t := range taken {
+		if method == t {
+			return method + "_"
+		}
+	}
+	return method
+}
+
+// tsIdent returns the name as a valid TypeScript identifier
+func tsIdent(name string) string {
+	ident := unexportedName(name)
+	if tsReserved[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+const tsRuntime = `/** Result of a call in a batch. */
+export type Result<T, E extends RpcErrorObject> =
+  | { ok: true; result: T }
+  | { ok: false; error: E };
+
+/** JSONRPCError is thrown by the client if the server responds with an error. */
+export class JSONRPCError<E extends RpcErrorObject = RpcErrorObject> extends Error {
+  readonly error: E;
+  readonly code: E["code"];
+  readonly data: E["data"];
+
+  constructor(error: E) {
+    super(error.message);
+    this.name = "JSONRPCError";
+    this.error = error;
+    this.code = error.code;
+    this.data = error.data;
+  }
+}
+
+/** isJSONRPCError reports whether the error is JSONRPCError with the code. */
+export function isJSONRPCError<C extends number>(
+  error: unknown,
+  code: C,
+): error is JSONRPCError<RpcErrorObject<C>> {
+  return error instanceof JSONRPCError && error.code === code;
+}
+
+export interface ClientOptions {
+  /** Headers sent with every request. */
+  headers?: Record<string, string>;
+  /** Fetch implementation, the global fetch by default. */
+  fetch?: typeof fetch;
+}
+
+interface RpcRequest {
+  jsonrpc: "2.0";
+  method: string;
+  params?: unknown;
+  id: number;
+}
+
+interface RpcResponse {
+  jsonrpc: "2.0";
+  result?: unknown;
+  error?: RpcErrorObject;
+  id: number | null;
+}
+
+class BaseClient {
+  private _lastId = 0;
+  private readonly _url: string;
+  private readonly _options: ClientOptions;
+
+  constructor(url: string, options: ClientOptions = {}) {
+    this._url = url;
+    this._options = options;
+  }
+
+  protected async _call<T>(method: string, params?: unknown): Promise<T> {
+    const response = (await this._send(this._request(method, params))) as RpcResponse;
+    if (response.error) {
+      throw new JSONRPCError(response.error);
+    }
+    return response.result as T;
+  }
+
+  /** @internal */
+  _request(method: string, params?: unknown): RpcRequest {
+    return { jsonrpc: "2.0", method, params, id: ++this._lastId };
+  }
+
+  /** @internal */
+  async _send(body: unknown): Promise<unknown> {
+    const f = this._options.fetch ?? fetch;
+    const response = await f(this._url, {
+      method: "POST",
+      headers: { "Content-Type": "application/json", ...this._options.headers },
+      body: JSON.stringify(body),
+    });
+    if (response.status === 204) {
+      return undefined;
+    }
+    if (!response.ok) {
+      throw new Error(` + "`" + `jsonrpc: unexpected HTTP status ${response.status}` + "`" + `);
+    }
+    return response.json();
+  }
+}
+
+class BaseBatch {
+  private readonly _client: BaseClient;
+  private readonly _requests: RpcRequest[] = [];
+  private readonly _pending = new Map<number, (response: RpcResponse) => void>();
+
+  constructor(client: BaseClient) {
+    this._client = client;
+  }
+
+  protected _add<T, E extends RpcErrorObject>(method: string, params?: unknown): Promise<Result<T, E>> {
+    const request = this._client._request(method, params);
+    this._requests.push(request);
+    return new Promise((resolve) => {
+      this._pending.set(request.id, (response) =>
+        resolve(
+          response.error
+            ? { ok: false, error: response.error as E }
+            : { ok: true, result: response.result as T },
+        ),
+      );
+    });
+  }
+
+  /**
+   * send sends the calls of the batch and resolves their promises. It rejects
+   * if the batch could not be sent.
+   */
+  async send(): Promise<void> {
+    if (this._requests.length === 0) {
+      return;
+    }
+    const responses = await this._client._send(this._requests);
+    if (!Array.isArray(responses)) {
+      const response = responses as RpcResponse | undefined;
+      if (response && response.error) {
+        throw new JSONRPCError(response.error);
+      }
+      throw new Error("jsonrpc: invalid batch response");
+    }
+
+    for (const response of responses as RpcResponse[]) {
+      const resolve = response.id === null ? undefined : this._pending.get(response.id);
+      if (resolve) {
+        resolve(response);
+        this._pending.delete(response.id as number);
+      }
+    }
+    this._pending.forEach((resolve) =>
+      resolve({ jsonrpc: "2.0", id: null, error: { code: -32603, message: "no response to the call" } }),
+    );
+    this._pending.clear();
+  }
+}
+
+`
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func TestGenerateTypeScriptFromInterface(t *testing.T) {
+	a, err := loadInterface(filepath.Join("testdata", "calc"), "Calculator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "calc_interface.ts.golden", generateTypeScript(a))
+}
+
+func TestGenerateTypeScriptFromOpenRPC(t *testing.T) {
+	a, err := loadOpenRPC(filepath.Join("testdata", "calc.openrpc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "calc_openrpc.ts.golden", generateTypeScript(a))
+}
+
+type greetRequest struct {
+	Name string `json:"name" description:"who is greeted" validate:"required"`
+}
+
+func TestGenerateTypeScriptFromDiscover(t *testing.T) {
+	greet := jsonrpc.Describe(jsonrpc.HandlerFunc(nil), jsonrpc.MethodDoc{
+		Summary: "Greets the caller",
+		Params:  jsonrpc.SchemaOf(greetRequest{}),
+		Result:  jsonrpc.SchemaOf(""),
+		Errors:  []jsonrpc.Error{{Code: 1, Message: "Unknown name"}},
+	})
+	server := httptest.NewServer(jsonrpc.NewServer(
+		jsonrpc.Handlers{"greet": greet},
+		jsonrpc.ServerIntrospection(jsonrpc.OpenRPCInfo{Title: "Greeter", Version: "1"}),
+	))
+	defer server.Close()
+
+	a, err := discover(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "greeter.ts.golden", generateTypeScript(a))
+}
+
+func TestGenerateTypeScriptCollidingNames(t *testing.T) {
+	a, err := loadOpenRPC(filepath.Join("testdata", "collisions.openrpc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "collisions.ts.golden", generateTypeScript(a))
+}
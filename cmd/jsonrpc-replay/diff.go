@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+// diffJSON compares the JSON documents ignoring formatting and the order of
+// object keys. The values at the ignore paths are not compared, for batch
+// responses the paths apply to every response in the batch.
+func diffJSON(expected, got json.RawMessage, ignore []string) []string {
+	expectedDoc, err := decode(ignorePaths(expected, ignore))
+	if err != nil {
+		return []string{fmt.Sprintf("recorded response: %s", err)}
+	}
+	gotDoc, err := decode(ignorePaths(got, ignore))
+	if err != nil {
+		return []string{fmt.Sprintf("response: %s", err)}
+	}
+
+	var diffs []string
+	diffValue("$", expectedDoc, gotDoc, &diffs)
+	return diffs
+}
+
+func ignorePaths(raw json.RawMessage, paths []string) json.RawMessage {
+	if len(paths) == 0 {
+		return raw
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		batch := make([]string, len(paths))
+		for i, p := range paths {
+			batch[i] = "*." + p
+		}
+		paths = batch
+	}
+	return jsonrpc.Redact(raw, paths...)
+}
+
+func decode(raw json.RawMessage) (interface{}, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func diffValue(path string, expected, got interface{}, diffs *[]string) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(g))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ev, eok := e[k]
+			gv, gok := g[k]
+			switch {
+			case !gok:
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: missing, expected %s", path, k, format(ev)))
+			case !eok:
+				*diffs = append(*diffs, fmt.Sprintf("%s.%s: unexpected %s", path, k, format(gv)))
+			default:
+				diffValue(path+"."+k, ev, gv, diffs)
+			}
+		}
+		return
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			break
+		}
+		if len(e) != len(g) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %d elements, got %d", path, len(e), len(g)))
+			return
+		}
+		for i := range e {
+			diffValue(path+"."+strconv.Itoa(i), e[i], g[i], diffs)
+		}
+		return
+	case json.Number:
+		if g, ok := got.(json.Number); ok && numberEqual(e, g) {
+			return
+		}
+	}
+
+	if format(expected) != format(got) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", path, format(expected), format(got)))
+	}
+}
+
+// numberEqual compares the numbers by value, e.g. 1 and 1.0 are equal
+func numberEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	x, okA := new(big.Float).SetString(string(a))
+	y, okB := new(big.Float).SetString(string(b))
+	return okA && okB && x.Cmp(y) == 0
+}
+
+func format(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		got      string
+		ignore   []string
+		diffs    []string
+	}{
+		{
+			name:     "equal with different formatting and key order",
+			expected: `{"jsonrpc":"2.0","result":{"a":1,"b":[1,2]},"id":1}`,
+			got:      `{"id":1, "result":{"b":[1, 2],"a":1.0e0}, "jsonrpc":"2.0"}`,
+		},
+		{
+			name:     "changed, missing and unexpected values",
+			expected: `{"result":{"a":1,"b":2}}`,
+			got:      `{"result":{"a":3,"c":4}}`,
+			diffs: []string{
+				"$.result.a: expected 1, got 3",
+				"$.result.b: missing, expected 2",
+				"$.result.c: unexpected 4",
+			},
+		},
+		{
+			name:     "ignored paths in batch",
+			expected: `[{"result":{"at":"1","n":1}},{"result":{"at":"2","n":2}}]`,
+			got:      `[{"result":{"at":"3","n":1}},{"result":{"at":"4","n":5}}]`,
+			ignore:   []string{"result.at"},
+			diffs:    []string{"$.1.result.n: expected 2, got 5"},
+		},
+		{
+			name:     "different length",
+			expected: `[1,2]`,
+			got:      `[1]`,
+			diffs:    []string{"$: expected 2 elements, got 1"},
+		},
+		{
+			name:     "no content",
+			expected: ``,
+			got:      ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := diffJSON(json.RawMessage(tt.expected), json.RawMessage(tt.got), tt.ignore)
+			if !reflect.DeepEqual(diffs, tt.diffs) {
+				t.Errorf("Expected %q, got %q", tt.diffs, diffs)
+			}
+		})
+	}
+}
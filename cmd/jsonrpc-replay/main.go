@@ -0,0 +1,215 @@
+// Command jsonrpc-replay re-sends the requests recorded by jsonrpc.Recorder to
+// a server and reports the responses which differ from the recorded ones.
+//
+// Usage:
+//
+//	jsonrpc-replay [flags] URL FILE
+//
+// The exit code is 1 if any response differs and 2 on errors.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string     { return strings.Join(*f, ", ") }
+func (f *stringsFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+func main() {
+	var (
+		headers      stringsFlag
+		ignore       stringsFlag
+		noHeaders    = flag.Bool("no-recorded-headers", false, "do not send the recorded request headers")
+		ignoreStatus = flag.Bool("ignore-status", false, "do not compare HTTP status codes")
+		timeout      = flag.Duration("timeout", 30*time.Second, "timeout of a single request")
+		verbose      = flag.Bool("v", false, "print every replayed request")
+	)
+	flag.Var(&headers, "H", "header sent with every request, e.g. -H 'Authorization: Bearer x' (repeatable)")
+	flag.Var(&ignore, "ignore", "path in the response ignored by the comparison, e.g. result.updatedAt (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] URL FILE\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	extra, err := parseHeaders(headers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer f.Close()
+
+	r := &replayer{
+		url:            flag.Arg(0),
+		client:         &http.Client{Timeout: *timeout},
+		header:         extra,
+		recordedHeader: !*noHeaders,
+		compareStatus:  !*ignoreStatus,
+		ignore:         ignore,
+		verbose:        *verbose,
+		out:            os.Stdout,
+	}
+
+	stats, err := r.replay(f)
+	fmt.Fprintf(os.Stdout, "replayed %d, differ %d, failed %d\n", stats.replayed, stats.differ, stats.failed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if stats.failed > 0 {
+		os.Exit(2)
+	}
+	if stats.differ > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseHeaders(values []string) (http.Header, error) {
+	header := http.Header{}
+	for _, v := range values {
+		i := strings.Index(v, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid header %q, expected 'Name: value'", v)
+		}
+		header.Add(strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1:]))
+	}
+	return header, nil
+}
+
+type stats struct {
+	replayed int
+	differ   int
+	failed   int
+}
+
+type replayer struct {
+	url            string
+	client         *http.Client
+	header         http.Header
+	recordedHeader bool
+	compareStatus  bool
+	ignore         []string
+	verbose        bool
+	out            io.Writer
+}
+
+// replay sends all recordings from the reader
+func (r *replayer) replay(in io.Reader) (stats, error) {
+	var st stats
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var rec jsonrpc.Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return st, fmt.Errorf("line %d: %s", line, err)
+		}
+
+		st.replayed++
+		diffs, err := r.replayOne(rec)
+		switch {
+		case err != nil:
+			st.failed++
+			fmt.Fprintf(r.out, "line %d %s: %s\n", line, describe(rec), err)
+		case len(diffs) > 0:
+			st.differ++
+			fmt.Fprintf(r.out, "line %d %s: response differs\n", line, describe(rec))
+			for _, d := range diffs {
+				fmt.Fprintf(r.out, "\t%s\n", d)
+			}
+		case r.verbose:
+			fmt.Fprintf(r.out, "line %d %s: ok\n", line, describe(rec))
+		}
+	}
+	return st, scanner.Err()
+}
+
+// replayOne sends the recorded request and compares the response
+func (r *replayer) replayOne(rec jsonrpc.Recording) ([]string, error) {
+	body := []byte(rec.Request)
+	if rec.InvalidRequest != "" {
+		body = []byte(rec.InvalidRequest)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if r.recordedHeader {
+		for k, values := range rec.Header {
+			if k == "Content-Length" {
+				continue
+			}
+			for _, v := range values {
+				if v != jsonrpc.Redacted {
+					req.Header.Add(k, v)
+				}
+			}
+		}
+	}
+	for k, values := range r.header {
+		req.Header[k] = values
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	got, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	if r.compareStatus && resp.StatusCode != rec.StatusCode {
+		diffs = append(diffs, fmt.Sprintf("status: expected %d, got %d", rec.StatusCode, resp.StatusCode))
+	}
+	return append(diffs, diffJSON(rec.Response, bytes.TrimSpace(got), r.ignore)...), nil
+}
+
+// describe returns the method or the batch size of the recorded request
+func describe(rec jsonrpc.Recording) string {
+	var req jsonrpc.Request
+	if err := json.Unmarshal(rec.Request, &req); err == nil && req.Method != "" {
+		return "(" + req.Method + ")"
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(rec.Request, &batch); err == nil {
+		return fmt.Sprintf("(batch of %d)", len(batch))
+	}
+	return "(invalid request)"
+}
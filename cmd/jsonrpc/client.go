@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      *int            `json:"id,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpc.Error  `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type client struct {
+	url    string
+	header http.Header
+	http   *http.Client
+	lastID int
+}
+
+// newRequest returns the request with the next id, notifications have no id
+func (c *client) newRequest(method string, params json.RawMessage, notification bool) request {
+	r := request{JSONRPC: jsonrpc.Version, Method: method, Params: params}
+	if !notification {
+		c.lastID++
+		id := c.lastID
+		r.ID = &id
+	}
+	return r
+}
+
+// send posts the body and returns the response body, empty for notifications
+func (c *client) send(body interface{}) ([]byte, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", jsonrpc.ContentType)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+
+	if len(b) > 0 && !json.Valid(b) {
+		return nil, fmt.Errorf("unexpected response %s: %s", resp.Status, b)
+	}
+	if len(b) == 0 && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return b, nil
+}
+
+// call calls the method and returns the response
+func (c *client) call(method string, params json.RawMessage) (response, error) {
+	var resp response
+	b, err := c.send(c.newRequest(method, params, false))
+	if err != nil {
+		return resp, err
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return resp, fmt.Errorf("invalid response: %s", err)
+	}
+	return resp, nil
+}
+
+// methods returns the methods of the server using introspection, rpc.methods
+// or rpc.discover with OpenRPC document.
+func (c *client) methods() ([]string, error) {
+	resp, err := c.call("rpc.methods", nil)
+	if err == nil && resp.Error == nil {
+		var methods []string
+		if err := json.Unmarshal(resp.Result, &methods); err == nil {
+			return methods, nil
+		}
+	}
+
+	resp, err = c.call("rpc.discover", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var doc struct {
+		Methods []struct {
+			Name string `json:"name"`
+		} `json:"methods"`
+	}
+	if err := json.Unmarshal(resp.Result, &doc); err != nil {
+		return nil, fmt.Errorf("invalid rpc.discover result: %s", err)
+	}
+	methods := make([]string, len(doc.Methods))
+	for i, m := range doc.Methods {
+		methods[i] = m.Name
+	}
+	return methods, nil
+}
+
+// complete returns the methods starting with the prefix
+func complete(methods []string, prefix string) []string {
+	var matches []string
+	for _, m := range methods {
+		if strings.HasPrefix(m, prefix) {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,252 @@
+// Command jsonrpc calls JSON-RPC 2.0 methods over HTTP from the terminal.
+//
+// Usage:
+//
+//	jsonrpc call [flags] URL METHOD [PARAM ...]
+//	jsonrpc notify [flags] URL METHOD [PARAM ...]
+//	jsonrpc batch [flags] URL FILE
+//	jsonrpc repl [flags] URL
+//
+// Params are given as key=value (string), key:=json (any JSON value) or "-"
+// to read the params from stdin. Arguments without "=" are positional params.
+//
+// The batch FILE ("-" for stdin) contains a JSON array of requests, the
+// "jsonrpc" member may be omitted.
+//
+// The exit code is 1 if the server responds with an error, also to a
+// notification, and 2 on usage or transport errors.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+const (
+	exitOK = iota
+	exitRPCError
+	exitFailure
+)
+
+const usage = `Usage:
+  jsonrpc call [flags] URL METHOD [PARAM ...]
+  jsonrpc notify [flags] URL METHOD [PARAM ...]
+  jsonrpc batch [flags] URL FILE
+  jsonrpc repl [flags] URL
+
+Params:
+  key=value    string value
+  key:=json    JSON value, e.g. count:=3
+  a.b=value    nested object
+  value        positional param
+  -            params read as JSON from stdin
+
+Run 'jsonrpc COMMAND -h' for the flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type headersFlag http.Header
+
+func (f headersFlag) String() string { return "" }
+
+func (f headersFlag) Set(v string) error {
+	i := strings.Index(v, ":")
+	if i <= 0 {
+		return fmt.Errorf("expected 'Name: value'")
+	}
+	http.Header(f).Add(strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1:]))
+	return nil
+}
+
+type cli struct {
+	client  *client
+	compact bool
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitFailure
+	}
+
+	command, args := args[0], args[1:]
+	var minArgs int
+	switch command {
+	case "call", "notify", "batch":
+		minArgs = 2
+	case "repl":
+		minArgs = 1
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", command, usage)
+		return exitFailure
+	}
+
+	header := http.Header{}
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Var(headersFlag(header), "H", "request header, e.g. -H 'Authorization: Bearer x' (repeatable)")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of a single request")
+	compact := fs.Bool("compact", false, "print compact JSON instead of indented")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	if fs.NArg() < minArgs {
+		fmt.Fprint(stderr, usage)
+		return exitFailure
+	}
+
+	c := &cli{
+		client: &client{
+			url:    fs.Arg(0),
+			header: header,
+			http:   &http.Client{Timeout: *timeout},
+		},
+		compact: *compact,
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+
+	switch command {
+	case "call":
+		return c.call(fs.Arg(1), fs.Args()[2:], false)
+	case "notify":
+		return c.call(fs.Arg(1), fs.Args()[2:], true)
+	case "batch":
+		return c.batch(fs.Arg(1))
+	default:
+		return c.repl()
+	}
+}
+
+// call calls the method and prints the result or the error
+func (c *cli) call(method string, args []string, notification bool) int {
+	params, err := parseParams(args, c.stdin)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitFailure
+	}
+
+	b, err := c.client.send(c.client.newRequest(method, params, notification))
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitFailure
+	}
+	// the server responds to a notification only if it is invalid
+	if notification && len(bytes.TrimSpace(b)) == 0 {
+		return exitOK
+	}
+
+	var resp response
+	if err := json.Unmarshal(b, &resp); err != nil {
+		fmt.Fprintf(c.stderr, "invalid response: %s\n", err)
+		return exitFailure
+	}
+	if notification && resp.Error == nil {
+		return exitOK
+	}
+	return c.printResponse(resp)
+}
+
+// batch sends the requests from the file and prints the responses
+func (c *cli) batch(file string) int {
+	var (
+		b   []byte
+		err error
+	)
+	if file == "-" {
+		b, err = ioutil.ReadAll(c.stdin)
+	} else {
+		b, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitFailure
+	}
+
+	var requests []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &requests); err != nil {
+		fmt.Fprintf(c.stderr, "batch must be a JSON array of requests: %s\n", err)
+		return exitFailure
+	}
+	for _, r := range requests {
+		if _, ok := r["jsonrpc"]; !ok {
+			r["jsonrpc"] = json.RawMessage(`"` + jsonrpc.Version + `"`)
+		}
+	}
+
+	b, err = c.client.send(requests)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitFailure
+	}
+	if len(b) == 0 {
+		return exitOK
+	}
+
+	var responses []response
+	if err := json.Unmarshal(b, &responses); err != nil {
+		// the server responds with a single error to an invalid batch
+		var resp response
+		if json.Unmarshal(b, &resp) == nil && resp.Error != nil {
+			return c.printResponse(resp)
+		}
+		fmt.Fprintf(c.stderr, "invalid response: %s\n", err)
+		return exitFailure
+	}
+
+	c.printJSON(c.stdout, b)
+	for _, resp := range responses {
+		if resp.Error != nil {
+			return exitRPCError
+		}
+	}
+	return exitOK
+}
+
+// printResponse prints the result to stdout or the error to stderr
+func (c *cli) printResponse(resp response) int {
+	if resp.Error != nil {
+		fmt.Fprintf(c.stderr, "error %d: %s\n", resp.Error.Code, resp.Error.Message)
+		if resp.Error.Data != nil {
+			b, _ := json.Marshal(resp.Error.Data)
+			c.printJSON(c.stderr, b)
+		}
+		return exitRPCError
+	}
+	c.printJSON(c.stdout, resp.Result)
+	return exitOK
+}
+
+func (c *cli) printJSON(w io.Writer, b []byte) {
+	if len(b) == 0 {
+		b = []byte("null")
+	}
+	var buf bytes.Buffer
+	if c.compact {
+		json.Compact(&buf, b)
+	} else {
+		json.Indent(&buf, b, "", "  ")
+	}
+	buf.WriteByte('\n')
+	buf.WriteTo(w)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type handlerFunc func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error)
+
+func (h handlerFunc) ServeJSONRPC(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+	return h(ctx, requestHeader, params)
+}
+
+func testServer() *httptest.Server {
+	return httptest.NewServer(jsonrpc.NewServer(jsonrpc.Handlers{
+		"echo": handlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+			return params, nil, nil
+		}),
+		"whoami": handlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+			b, err := json.Marshal(requestHeader.Get("X-User"))
+			return b, nil, err
+		}),
+		"fail": handlerFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			return nil, nil, jsonrpc.NewInvalidParamsError()
+		}),
+	}, jsonrpc.ServerIntrospection(jsonrpc.OpenRPCInfo{Title: "Test", Version: "1"})))
+}
+
+func TestRun(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "call",
+			args:   []string{"call", server.URL, "echo", "a:=1", "b=x"},
+			stdout: "{\n  \"a\": 1,\n  \"b\": \"x\"\n}\n",
+		},
+		{
+			name:   "compact",
+			args:   []string{"call", "-compact", server.URL, "echo", "-"},
+			stdin:  `{"a": [1, 2]}`,
+			stdout: "{\"a\":[1,2]}\n",
+		},
+		{
+			name:   "header",
+			args:   []string{"call", "-H", "X-User: john", server.URL, "whoami"},
+			stdout: "\"john\"\n",
+		},
+		{
+			name:   "error",
+			args:   []string{"call", server.URL, "fail"},
+			code:   exitRPCError,
+			stderr: "error -32602: Invalid method parameter(s)\n",
+		},
+		{
+			name: "notification",
+			args: []string{"notify", server.URL, "echo"},
+		},
+		{
+			name:   "notification error",
+			args:   []string{"notify", server.URL, "missing"},
+			code:   exitRPCError,
+			stderr: "error -32601: The method does not exist / is not available\n",
+		},
+		{
+			name:   "batch",
+			args:   []string{"batch", "-compact", server.URL, "-"},
+			stdin:  `[{"method":"echo","params":[1],"id":1},{"method":"fail","id":2},{"method":"echo"}]`,
+			code:   exitRPCError,
+			stdout: "[{\"jsonrpc\":\"2.0\",\"result\":[1],\"id\":1},{\"jsonrpc\":\"2.0\",\"error\":{\"code\":-32602,\"message\":\"Invalid method parameter(s)\"},\"id\":2}]\n",
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"get", server.URL},
+			code:   exitFailure,
+			stderr: "unknown command \"get\"",
+		},
+		{
+			name:   "transport error",
+			args:   []string{"call", "http://127.0.0.1:0", "echo"},
+			code:   exitFailure,
+			stderr: "connect",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			code := run(tt.args, strings.NewReader(tt.stdin), stdout, stderr)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d, stderr: %s", tt.code, code, stderr)
+			}
+			if got := stdout.String(); got != tt.stdout {
+				t.Errorf("Expected stdout %q, got %q", tt.stdout, got)
+			}
+			if got := stderr.String(); !strings.Contains(got, tt.stderr) {
+				t.Errorf("Expected stderr to contain %q, got %q", tt.stderr, got)
+			}
+		})
+	}
+}
+
+func TestREPL(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	stdin := strings.Join([]string{
+		":header X-User: jane",
+		"who",
+		"echo a=1",
+		"echo -",
+		"e?",
+		":quit",
+	}, "\n")
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"repl", "-compact", server.URL}, strings.NewReader(stdin), stdout, stderr); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+
+	for _, expect := range []string{"-> whoami\n", "\"jane\"\n", "{\"a\":\"1\"}\n", "> echo\n"} {
+		if !strings.Contains(stdout.String(), expect) {
+			t.Errorf("Expected stdout to contain %q, got %q", expect, stdout)
+		}
+	}
+	if expect := "params cannot be read from stdin"; !strings.Contains(stderr.String(), expect) {
+		t.Errorf("Expected stderr to contain %q, got %q", expect, stderr)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	methods := []string{"user.get", "user.list", "order.get"}
+	if got := complete(methods, "user."); len(got) != 2 {
+		t.Errorf("Expected 2 matches, got %q", got)
+	}
+	if got := complete(methods, "ord"); len(got) != 1 || got[0] != "order.get" {
+		t.Errorf("Expected order.get, got %q", got)
+	}
+}
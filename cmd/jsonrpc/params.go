@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// parseParams builds the params from the arguments:
+//
+//	key=value    string value
+//	key:=json    JSON value, e.g. count:=3, tags:='["a","b"]'
+//	a.b=value    nested object {"a":{"b":"value"}}
+//	-            params read as JSON from stdin
+//
+// Arguments without "=" are positional params, the values are parsed as JSON
+// if possible, otherwise used as strings. Named and positional params cannot
+// be mixed. Nil is returned without arguments.
+func parseParams(args []string, stdin io.Reader) (json.RawMessage, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	if len(args) == 1 && args[0] == "-" {
+		b, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		b = bytes.TrimSpace(b)
+		if !json.Valid(b) {
+			return nil, fmt.Errorf("params from stdin are not valid JSON")
+		}
+		return b, nil
+	}
+
+	var (
+		named      = map[string]interface{}{}
+		positional []interface{}
+	)
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i <= 0 {
+			positional = append(positional, positionalValue(arg))
+			continue
+		}
+
+		key, value := arg[:i], interface{}(arg[i+1:])
+		if strings.HasSuffix(key, ":") {
+			key = strings.TrimSuffix(key, ":")
+			var v interface{}
+			if err := json.Unmarshal([]byte(arg[i+1:]), &v); err != nil {
+				return nil, fmt.Errorf("invalid JSON value of %q: %s", key, err)
+			}
+			value = v
+		}
+		if err := setPath(named, strings.Split(key, "."), value); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(positional) > 0 && len(named) > 0 {
+		return nil, fmt.Errorf("named and positional params cannot be mixed")
+	}
+	if len(positional) > 0 {
+		return json.Marshal(positional)
+	}
+	return json.Marshal(named)
+}
+
+func positionalValue(arg string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(arg), &v); err != nil {
+		return arg
+	}
+	return v
+}
+
+func setPath(obj map[string]interface{}, path []string, value interface{}) error {
+	key := path[0]
+	if key == "" {
+		return fmt.Errorf("empty key in %q", strings.Join(path, "."))
+	}
+	if len(path) == 1 {
+		obj[key] = value
+		return nil
+	}
+
+	child, ok := obj[key].(map[string]interface{})
+	if !ok {
+		if _, exists := obj[key]; exists {
+			return fmt.Errorf("%q is already set to a value", key)
+		}
+		child = map[string]interface{}{}
+		obj[key] = child
+	}
+	return setPath(child, path[1:], value)
+}
+
+// splitArgs splits the line into arguments separated by spaces, single or
+// double quotes group words, e.g. tags:='["a", "b"]'
+func splitArgs(line string) ([]string, error) {
+	var (
+		args  []string
+		arg   strings.Builder
+		quote rune
+		inArg bool
+	)
+	for _, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inArg = c, true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		params string
+		err    bool
+	}{
+		{name: "no params"},
+		{
+			name:   "named",
+			args:   []string{"name=John", "age:=42", "tags:=[\"a\"]", "address.city=Prague", "address.zip:=11000"},
+			params: `{"address":{"city":"Prague","zip":11000},"age":42,"name":"John","tags":["a"]}`,
+		},
+		{
+			name:   "string with equal sign",
+			args:   []string{"query=a=b"},
+			params: `{"query":"a=b"}`,
+		},
+		{
+			name:   "positional",
+			args:   []string{"1", "two", "true", "{\"a\":1}"},
+			params: `[1,"two",true,{"a":1}]`,
+		},
+		{
+			name:   "stdin",
+			args:   []string{"-"},
+			stdin:  " {\"a\": 1}\n",
+			params: `{"a": 1}`,
+		},
+		{name: "invalid stdin", args: []string{"-"}, stdin: "{", err: true},
+		{name: "invalid JSON value", args: []string{"a:={"}, err: true},
+		{name: "mixed", args: []string{"a=1", "2"}, err: true},
+		{name: "key already set", args: []string{"a=1", "a.b=2"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := parseParams(tt.args, strings.NewReader(tt.stdin))
+			if tt.err {
+				if err == nil {
+					t.Errorf("Expected error, got params %s", params)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(params); got != tt.params {
+				t.Errorf("Expected %s, got %s", tt.params, got)
+			}
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`add  a:=1 "b=two words" tags:='["x", "y"]'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"add", "a:=1", "b=two words", `tags:=["x", "y"]`}
+	if !reflect.DeepEqual(args, expect) {
+		t.Errorf("Expected %q, got %q", expect, args)
+	}
+
+	if _, err := splitArgs(`a "b`); err == nil {
+		t.Error("Expected error for unterminated quote")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+const replHelp = `Commands:
+  METHOD [PARAM ...]         call the method, a unique prefix of the method is completed
+  PREFIX?                    list the methods starting with the prefix
+  :notify METHOD [PARAM ...] send notification
+
+Params are given as on the command line, except "-".
+  :methods                   list the methods
+  :header Name: value        set the header of the following requests
+  :help                      print this help
+  :quit                      exit
+`
+
+// repl reads the calls from stdin until EOF or :quit. The methods are
+// completed if the server supports introspection.
+func (c *cli) repl() int {
+	methods, err := c.client.methods()
+	if err != nil {
+		fmt.Fprintf(c.stderr, "method completion disabled: %s\n", err)
+	}
+
+	fmt.Fprintf(c.stdout, "Connected to %s, type :help for help.\n", c.client.url)
+	scanner := bufio.NewScanner(c.stdin)
+	for {
+		fmt.Fprint(c.stdout, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(c.stdout)
+			return exitOK
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case line == ":quit" || line == ":q" || line == ":exit":
+			return exitOK
+		case line == ":help":
+			fmt.Fprint(c.stdout, replHelp)
+		case line == ":methods":
+			for _, m := range methods {
+				fmt.Fprintln(c.stdout, m)
+			}
+		case strings.HasPrefix(line, ":header "):
+			if err := headersFlag(c.client.header).Set(strings.TrimPrefix(line, ":header ")); err != nil {
+				fmt.Fprintln(c.stderr, err)
+			}
+		case strings.HasSuffix(line, "?") && !strings.ContainsAny(line, " \t"):
+			for _, m := range complete(methods, strings.TrimSuffix(line, "?")) {
+				fmt.Fprintln(c.stdout, m)
+			}
+		case strings.HasPrefix(line, ":notify "):
+			c.replCall(methods, strings.TrimPrefix(line, ":notify "), true)
+		case strings.HasPrefix(line, ":"):
+			fmt.Fprintf(c.stderr, "unknown command %s, type :help for help\n", line)
+		default:
+			c.replCall(methods, line, false)
+		}
+	}
+}
+
+func (c *cli) replCall(methods []string, line string, notification bool) {
+	args, err := splitArgs(line)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(c.stderr, "missing method")
+		return
+	}
+
+	if len(args) == 2 && args[1] == "-" {
+		fmt.Fprintln(c.stderr, "params cannot be read from stdin in the REPL, use key:=json")
+		return
+	}
+
+	method := args[0]
+	if matches := complete(methods, method); len(matches) == 1 && matches[0] != method {
+		method = matches[0]
+		fmt.Fprintf(c.stdout, "-> %s\n", method)
+	}
+
+	c.call(method, args[1:], notification)
+}
@@ -0,0 +1,110 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// RequestInfo describes the call served by Server. It is stored in the
+// context for every call, including notifications and batch elements.
+type RequestInfo struct {
+	Method       string
+	ID           *RequestID
+	Notification bool
+
+	// Batch reports whether the call is an element of a batch, BatchIndex is
+	// its position and BatchSize the number of elements in the batch.
+	Batch      bool
+	BatchIndex int
+	BatchSize  int
+
+	RemoteAddr string
+	ReceivedAt time.Time
+}
+
+// RequestInfoFromContext returns the RequestInfo stored in the context by
+// Server.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(ContextKeyRequestInfo).(RequestInfo)
+	return info, ok
+}
+
+// MethodFromContext returns the method stored in the context by
+// PopulateRequestContext.
+func MethodFromContext(ctx context.Context) (string, bool) {
+	method, ok := ctx.Value(ContextKeyRequestMethod).(string)
+	return method, ok
+}
+
+// RequestIDFromContext returns the RequestID stored in the context by
+// PopulateRequestContext. It returns false for notifications.
+func RequestIDFromContext(ctx context.Context) (*RequestID, bool) {
+	id, ok := ctx.Value(ContextKeyRequestID).(*RequestID)
+	return id, ok && id != nil
+}
+
+// IsNotification reports whether the call is a notification
+func IsNotification(ctx context.Context) bool {
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		return info.Notification
+	}
+	_, hasMethod := MethodFromContext(ctx)
+	_, hasID := RequestIDFromContext(ctx)
+	return hasMethod && !hasID
+}
+
+// BatchInfo returns the position of the call in the batch and the size of
+// the batch. It returns false if the call is not part of a batch.
+func BatchInfo(ctx context.Context) (index, size int, ok bool) {
+	info, _ := RequestInfoFromContext(ctx)
+	return info.BatchIndex, info.BatchSize, info.Batch
+}
+
+// ReceivedAt returns the time the Server received the HTTP request
+func ReceivedAt(ctx context.Context) (time.Time, bool) {
+	info, ok := RequestInfoFromContext(ctx)
+	return info.ReceivedAt, ok
+}
+
+// RawRequestFromContext returns the raw bytes of the HTTP request body stored
+// in the context by Server. The bytes must not be modified.
+func RawRequestFromContext(ctx context.Context) ([]byte, bool) {
+	body, ok := ctx.Value(ContextKeyRequestBody).([]byte)
+	return body, ok
+}
+
+// RequestFromContext returns the decoded Request stored in the context by
+// PopulateRequestContext.
+func RequestFromContext(ctx context.Context) (*Request, bool) {
+	r, ok := ctx.Value(ContextKeyRequest).(*Request)
+	return r, ok && r != nil
+}
+
+// RequestMessageFromContext returns the raw bytes of the Request object stored
+// in the context by Server. The bytes must not be modified.
+func RequestMessageFromContext(ctx context.Context) (json.RawMessage, bool) {
+	msg, ok := ctx.Value(ContextKeyRequestMessage).(json.RawMessage)
+	return msg, ok
+}
+
+// RequestExtensions returns the non-standard top-level members of the Request
+// object stored in the context by Server.
+func RequestExtensions(ctx context.Context) map[string]json.RawMessage {
+	msg, ok := RequestMessageFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &members); err != nil {
+		return nil
+	}
+	for _, name := range []string{"jsonrpc", "method", "params", "id"} {
+		delete(members, name)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return members
+}
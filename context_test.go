@@ -0,0 +1,79 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func TestRequestInfo(t *testing.T) {
+	before := time.Now()
+
+	h := HandlererFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		info, ok := jsonrpc.RequestInfoFromContext(ctx)
+		if !ok {
+			t.Fatal("Expecting request info in the context")
+		}
+
+		if got, expect := info.Method, testMethodName; got != expect {
+			t.Errorf("Expecting method %s, got %s", expect, got)
+		}
+		if method, _ := jsonrpc.MethodFromContext(ctx); method != testMethodName {
+			t.Errorf("Expecting method %s, got %s", testMethodName, method)
+		}
+
+		id, ok := jsonrpc.RequestIDFromContext(ctx)
+		if !ok {
+			t.Fatal("Expecting request id in the context")
+		}
+		if got, _ := id.Int(); got != 1 {
+			t.Errorf("Expecting request id 1, got %d", got)
+		}
+
+		if jsonrpc.IsNotification(ctx) {
+			t.Error("Expecting call not to be a notification")
+		}
+		if _, _, ok := jsonrpc.BatchInfo(ctx); ok {
+			t.Error("Expecting call not to be part of a batch")
+		}
+		if got, expect := info.RemoteAddr, "10.0.0.1:1234"; got != expect {
+			t.Errorf("Expecting remote address %s, got %s", expect, got)
+		}
+		if at, ok := jsonrpc.ReceivedAt(ctx); !ok || at.Before(before) {
+			t.Errorf("Expecting received at after %s, got %s", before, at)
+		}
+		return nil, nil, nil
+	})
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	r.RemoteAddr = "10.0.0.1:1234"
+	if _, err := testServer(r, h); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+}
+
+func TestContextAccessorsWithoutServer(t *testing.T) {
+	ctx := context.Background()
+
+	if _, ok := jsonrpc.MethodFromContext(ctx); ok {
+		t.Error("Expecting no method")
+	}
+	if _, ok := jsonrpc.RequestIDFromContext(ctx); ok {
+		t.Error("Expecting no request id")
+	}
+	if jsonrpc.IsNotification(ctx) {
+		t.Error("Expecting not a notification")
+	}
+
+	var req jsonrpc.Request
+	json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"name"}`), &req)
+	if !jsonrpc.IsNotification(jsonrpc.PopulateRequestContext(ctx, &req)) {
+		t.Error("Expecting a notification")
+	}
+}
@@ -0,0 +1,91 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// EndpointCodec defines an endpoint and the codecs used to serve it as a
+// JSON-RPC method.
+type EndpointCodec struct {
+	Endpoint endpoint.Endpoint
+	Decode   DecodeRequestFunc
+	Encode   EncodeResponseFunc
+
+	// Doc describes the method, the params and result schemas are derived
+	// from Request and Response if not set.
+	Doc MethodDoc
+
+	// Request and Response are values of the types decoded from the params
+	// and encoded as the result, e.g. AddRequest{}.
+	Request  interface{}
+	Response interface{}
+}
+
+// EndpointCodecMap maps the method name to the endpoint and its codecs
+type EndpointCodecMap map[string]EndpointCodec
+
+// NewHandlers constructs Handlers from the endpoint codecs. The middlewares are
+// applied to every endpoint, the first one being the outermost, and the options
+// are passed to every constructed Handler.
+func NewHandlers(
+	ecm EndpointCodecMap,
+	middlewares []endpoint.Middleware,
+	options ...HandlerOption,
+) Handlers {
+	h := Handlers{}
+	for method, ec := range ecm {
+		e := ec.Endpoint
+		if len(middlewares) > 0 {
+			e = endpoint.Chain(middlewares[0], middlewares[1:]...)(e)
+		}
+		h.Set(method, NewHandler(e, ec.Decode, ec.Encode, append([]HandlerOption{HandlerDoc(ec.doc())}, options...)...))
+	}
+	return h
+}
+
+// doc returns Doc with the schemas derived from Request and Response
+func (ec EndpointCodec) doc() MethodDoc {
+	doc := ec.Doc
+	if doc.Params == nil && ec.Request != nil {
+		doc.Params = SchemaOf(ec.Request)
+	}
+	if doc.Result == nil && ec.Response != nil {
+		doc.Result = SchemaOf(ec.Response)
+	}
+	return doc
+}
+
+// HandlerEndpoint exposes the Handlerer as an endpoint. The request has to be
+// json.RawMessage holding the params and the response is json.RawMessage
+// returned by the Handlerer. Request header is taken from the context if it
+// was stored with ContextWithRequestHeader, response header is discarded.
+func HandlerEndpoint(h Handlerer) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		params, ok := request.(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("jsonrpc: unexpected endpoint request type %T", request)
+		}
+
+		header, _ := ctx.Value(contextKeyRequestHeader).(http.Header)
+		if header == nil {
+			header = http.Header{}
+		}
+
+		response, _, err := h.ServeJSONRPC(ctx, header, params)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+}
+
+// ContextWithRequestHeader stores the request header in the context, so it is
+// passed to the Handlerer wrapped by HandlerEndpoint.
+func ContextWithRequestHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, contextKeyRequestHeader, header)
+}
@@ -0,0 +1,95 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func TestNewHandlersAppliesMiddlewares(t *testing.T) {
+	var calls []string
+	middleware := func(name string) endpoint.Middleware {
+		return func(next endpoint.Endpoint) endpoint.Endpoint {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return next(ctx, request)
+			}
+		}
+	}
+
+	handlers := jsonrpc.NewHandlers(
+		jsonrpc.EndpointCodecMap{
+			"add": jsonrpc.EndpointCodec{
+				Endpoint: func(context.Context, interface{}) (interface{}, error) { return 3, nil },
+				Decode:   func(context.Context, json.RawMessage) (interface{}, error) { return nil, nil },
+				Encode:   func(_ context.Context, r interface{}) (json.RawMessage, error) { return json.Marshal(r) },
+			},
+		},
+		[]endpoint.Middleware{middleware("first"), middleware("second")},
+	)
+
+	h, ok := handlers["add"]
+	if !ok {
+		t.Fatal("Expected handler for method add")
+	}
+
+	resp, _, err := h.ServeJSONRPC(context.Background(), http.Header{}, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if got, expect := string(resp), "3"; got != expect {
+		t.Errorf("got %s, expect %s", got, expect)
+	}
+
+	if got, expect := len(calls), 2; got != expect {
+		t.Fatalf("got %d middleware calls, expect %d", got, expect)
+	}
+	if calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("Unexpected middleware order %v", calls)
+	}
+}
+
+func TestHandlerEndpoint(t *testing.T) {
+	h := HandlererFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		if got, expect := requestHeader.Get("X-Foo"), "bar"; got != expect {
+			t.Errorf("Expect header %s, got %s", expect, got)
+		}
+		return params, nil, nil
+	})
+
+	e := jsonrpc.HandlerEndpoint(h)
+
+	ctx := jsonrpc.ContextWithRequestHeader(context.Background(), http.Header{"X-Foo": []string{"bar"}})
+	resp, err := e(ctx, json.RawMessage(`{"a":"b"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	raw, ok := resp.(json.RawMessage)
+	if !ok {
+		t.Fatalf("Expected json.RawMessage, got %T", resp)
+	}
+	if got, expect := string(raw), `{"a":"b"}`; got != expect {
+		t.Errorf("got %s, expect %s", got, expect)
+	}
+}
+
+func TestHandlerEndpointErrors(t *testing.T) {
+	e := jsonrpc.HandlerEndpoint(HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+		return nil, nil, errors.New("dang")
+	}))
+
+	if _, err := e(context.Background(), "not raw"); err == nil {
+		t.Error("Expected error for unexpected request type")
+	}
+
+	if _, err := e(context.Background(), json.RawMessage(`{}`)); err == nil || err.Error() != "dang" {
+		t.Errorf("Expected error dang, got %v", err)
+	}
+}
@@ -1,5 +1,16 @@
 package jsonrpc
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // ErrorCoder is checked by DefaultErrorEncoder. If an error value implements
 // ErrorCoder, the Error will be used when encoding the error. By default,
 // InternalError (-32603) is used.
@@ -20,6 +31,14 @@ type Error struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+
+	cause *errorCause
+}
+
+// errorCause is the wrapped error with the stack trace of the wrapping
+type errorCause struct {
+	err   error
+	stack []uintptr
 }
 
 // Error implements Errorer and error
@@ -32,6 +51,44 @@ func (e Error) ErrorCode() int {
 	return e.Code
 }
 
+// WithCause returns the copy of the Error wrapping the cause and the stack
+// trace of the call. The cause is logged by the Server, it is not sent to the
+// client, unless the Server is in debug mode and the Error has no Data, see
+// ServerDebug.
+func (e Error) WithCause(cause error) Error {
+	return e.withCause(cause, 3)
+}
+
+func (e Error) withCause(cause error, skip int) Error {
+	if cause == nil {
+		e.cause = nil
+		return e
+	}
+	stack := make([]uintptr, 32)
+	e.cause = &errorCause{err: cause, stack: stack[:runtime.Callers(skip, stack)]}
+	return e
+}
+
+// Unwrap returns the cause of the Error, see WithCause
+func (e Error) Unwrap() error {
+	if e.cause == nil {
+		return nil
+	}
+	return e.cause.err
+}
+
+// Is reports whether the target is an Error of the same code, so
+// errors.Is(err, NewTimeoutError()) reports whether err is a TimeoutError.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.Code == e.Code
+}
+
+// WrapError returns the Error of the code wrapping the cause, see WithCause
+func WrapError(code int, cause error, message ...string) Error {
+	return NewError(code, message...).withCause(cause, 3)
+}
+
 const (
 	// ParseError defines invalid JSON was received by the server.
 	// An error occurred on the server while parsing the JSON text.
@@ -48,15 +105,26 @@ const (
 
 	// InternalError defines a server error
 	InternalError int = -32603
-)
 
-var errorMessage = map[int]string{
-	ParseError:          "An error occurred on the server while parsing the JSON text",
-	InvalidRequestError: "The JSON sent is not a valid Request object",
-	MethodNotFoundError: "The method does not exist / is not available",
-	InvalidParamsError:  "Invalid method parameter(s)",
-	InternalError:       "Internal JSON-RPC error",
-}
+	// TimeoutError defines the server did not complete the call in time.
+	TimeoutError int = -32001
+
+	// ServerBusyError defines the server has no capacity to serve the call.
+	ServerBusyError int = -32002
+
+	// RateLimitedError defines the client exceeded the allowed rate of calls.
+	RateLimitedError int = -32003
+
+	// UnauthorizedError defines the client is not authenticated.
+	UnauthorizedError int = -32004
+
+	// ForbiddenError defines the client is not allowed to call the method.
+	ForbiddenError int = -32005
+
+	// CancelledError defines the call was cancelled before it completed,
+	// e.g. the client closed the connection.
+	CancelledError int = -32006
+)
 
 // NewError returns Error struct
 func NewError(code int, message ...string) Error {
@@ -70,13 +138,225 @@ func NewError(code int, message ...string) Error {
 	}
 }
 
-// ErrorMessage returns a message for the JSON RPC error code. It returns the empty
-// string if the code is unknown.
+// ErrorMessage returns a message for the JSON RPC error code, including the
+// codes registered by RegisterError. It returns the empty string if the code
+// is unknown.
 func ErrorMessage(code int) string {
-	return errorMessage[code]
+	d, _ := LookupError(code)
+	return d.Message
 }
 
 // NewInvalidParamsError is helper for returning InvalidParamsError
 func NewInvalidParamsError(msg ...string) Error {
 	return NewError(InvalidParamsError, msg...)
 }
+
+// NewTimeoutError is helper for returning TimeoutError
+func NewTimeoutError(msg ...string) Error {
+	return NewError(TimeoutError, msg...)
+}
+
+// NewUnauthorizedError is helper for returning UnauthorizedError
+func NewUnauthorizedError(msg ...string) Error {
+	return NewError(UnauthorizedError, msg...)
+}
+
+// NewForbiddenError is helper for returning ForbiddenError
+func NewForbiddenError(msg ...string) Error {
+	return NewError(ForbiddenError, msg...)
+}
+
+// NewCancelledError is helper for returning CancelledError
+func NewCancelledError(msg ...string) Error {
+	return NewError(CancelledError, msg...)
+}
+
+// InvalidParamsData is set as the Data of InvalidParamsError responses which
+// list the invalid values of the params, e.g.
+//
+//	{
+//		"code": -32602,
+//		"message": "Invalid method parameter(s)",
+//		"data": {
+//			"violations": [
+//				{"path": "/items/0/quantity", "code": "minimum", "message": "must be greater than or equal to 1", "value": 0},
+//				{"path": "/email", "code": "required", "message": "is required"}
+//			]
+//		}
+//	}
+//
+// Use InvalidParamsBuilder to return it and InvalidParamsViolations to read
+// it from the errors returned to the clients.
+type InvalidParamsData struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Violation describes the invalid value of the params
+type Violation struct {
+	// Path is the JSON Pointer (RFC 6901) of the value within the params,
+	// empty for the params themselves, see JSONPointer.
+	Path string `json:"path"`
+
+	// Code identifies the kind of the violation for the programs, e.g. the
+	// JSON Schema keyword or the rule of the validate tag, like "required".
+	Code string `json:"code,omitempty"`
+
+	// Message describes the violation for the humans
+	Message string `json:"message"`
+
+	// Value is the rejected value, it is omitted for objects and arrays
+	Value interface{} `json:"value,omitempty"`
+}
+
+// InvalidParamsBuilder collects the violations of the params. The zero value
+// is ready to use, e.g.
+//
+//	var b jsonrpc.InvalidParamsBuilder
+//	if req.Quantity < 1 {
+//		b.Add(jsonrpc.JSONPointer("quantity"), "minimum", "must be at least 1", req.Quantity)
+//	}
+//	if err := b.Err(); err != nil {
+//		return nil, err
+//	}
+type InvalidParamsBuilder struct {
+	violations []Violation
+}
+
+// Add adds the violation of the value at the path
+func (b *InvalidParamsBuilder) Add(path, code, message string, value interface{}) *InvalidParamsBuilder {
+	b.violations = append(b.violations, Violation{
+		Path:    path,
+		Code:    code,
+		Message: message,
+		Value:   value,
+	})
+	return b
+}
+
+// Violations returns the added violations
+func (b *InvalidParamsBuilder) Violations() []Violation {
+	return b.violations
+}
+
+// Err returns InvalidParamsError with the InvalidParamsData of the
+// violations, nil if there are none
+func (b *InvalidParamsBuilder) Err() error {
+	if len(b.violations) == 0 {
+		return nil
+	}
+	e := NewInvalidParamsError()
+	e.Data = InvalidParamsData{Violations: b.violations}
+	return e
+}
+
+// JSONPointer returns the JSON Pointer (RFC 6901) of the names of the
+// properties and the indexes of the items, e.g. JSONPointer("items", 0, "a/b")
+// is "/items/0/a~1b".
+func JSONPointer(tokens ...interface{}) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escapePointer(fmt.Sprint(token)))
+	}
+	return b.String()
+}
+
+// InvalidParamsViolations returns the violations of the InvalidParamsError in
+// the error chain, false if there is none. The Data is decoded either from
+// InvalidParamsData or from its JSON, as decoded by the clients, e.g.
+// map[string]interface{} or json.RawMessage.
+func InvalidParamsViolations(err error) ([]Violation, bool) {
+	var e Error
+	if !errors.As(err, &e) || e.Code != InvalidParamsError {
+		return nil, false
+	}
+
+	switch data := e.Data.(type) {
+	case InvalidParamsData:
+		return data.Violations, true
+	case *InvalidParamsData:
+		if data == nil {
+			return nil, false
+		}
+		return data.Violations, true
+	case nil:
+		return nil, false
+	}
+
+	raw, ok := e.Data.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(e.Data); err != nil {
+			return nil, false
+		}
+	}
+	var data InvalidParamsData
+	if err := json.Unmarshal(raw, &data); err != nil || data.Violations == nil {
+		return nil, false
+	}
+	return data.Violations, true
+}
+
+// RetryAfterData is set as the Data of errors returned when the call may be
+// retried later, e.g. ServerBusyError.
+type RetryAfterData struct {
+	// RetryAfter is the number of seconds the client should wait before
+	// retrying the call.
+	RetryAfter int `json:"retryAfter"`
+}
+
+// NewServerBusyError is helper for returning ServerBusyError with the
+// RetryAfterData
+func NewServerBusyError(retryAfter time.Duration) Error {
+	e := NewError(ServerBusyError)
+	e.Data = RetryAfterData{RetryAfter: retryAfterSeconds(retryAfter)}
+	return e
+}
+
+// NewRateLimitedError is helper for returning RateLimitedError with the
+// RetryAfterData
+func NewRateLimitedError(retryAfter time.Duration) Error {
+	e := NewError(RateLimitedError)
+	e.Data = RetryAfterData{RetryAfter: retryAfterSeconds(retryAfter)}
+	return e
+}
+
+// retryAfterSeconds rounds the duration up to whole seconds, at least one.
+func retryAfterSeconds(d time.Duration) int {
+	sec := int((d + time.Second - 1) / time.Second)
+	if sec < 1 {
+		return 1
+	}
+	return sec
+}
+
+// headerError decorates the Error with headers set on the HTTP response by
+// DefaultErrorEncoder.
+type headerError struct {
+	err    Error
+	header http.Header
+}
+
+// Error implements error
+func (e headerError) Error() string { return e.err.Error() }
+
+// ErrorCode implements ErrorCoder
+func (e headerError) ErrorCode() int { return e.err.ErrorCode() }
+
+// Headers implements Headerer
+func (e headerError) Headers() http.Header { return e.header }
+
+// Unwrap returns the decorated Error
+func (e headerError) Unwrap() error { return e.err }
+
+// withRetryAfter sets the Retry-After header for the Error with RetryAfterData
+func withRetryAfter(e Error) error {
+	data, ok := e.Data.(RetryAfterData)
+	if !ok {
+		return e
+	}
+	return headerError{
+		err:    e,
+		header: http.Header{"Retry-After": []string{strconv.Itoa(data.RetryAfter)}},
+	}
+}
@@ -2,7 +2,10 @@ package jsonrpc_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"testing"
 
 	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
@@ -19,6 +22,7 @@ func TestPredefinedErrors(t *testing.T) {
 		{jsonrpc.MethodNotFoundError, "The method does not exist / is not available"},
 		{jsonrpc.InvalidParamsError, "Invalid method parameter(s)"},
 		{jsonrpc.InternalError, "Internal JSON-RPC error"},
+		{jsonrpc.CancelledError, "Request cancelled"},
 	}
 
 	for _, c := range cases {
@@ -100,3 +104,95 @@ func TestErrorHelperInvalidParams(t *testing.T) {
 		t.Errorf("Error(): expected %s, actual %s", expected, got)
 	}
 }
+
+func TestInvalidParamsBuilder(t *testing.T) {
+	var b jsonrpc.InvalidParamsBuilder
+	if err := b.Err(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	b.Add(jsonrpc.JSONPointer("items", 0, "a/b~"), "min", "must be at least 1", 0).
+		Add("", "custom", "is odd", nil)
+
+	data, err := json.Marshal(b.Err())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"code":-32602,"message":"Invalid method parameter(s)","data":{"violations":[` +
+		`{"path":"/items/0/a~1b~0","code":"min","message":"must be at least 1","value":0},` +
+		`{"path":"","code":"custom","message":"is odd"}]}}`
+	if got := string(data); got != expected {
+		t.Errorf("JSON: expected %s, actual %s", expected, got)
+	}
+}
+
+func TestInvalidParamsViolations(t *testing.T) {
+	var b jsonrpc.InvalidParamsBuilder
+	b.Add("/name", "required", "is required", "")
+	expected := []jsonrpc.Violation{{Path: "/name", Code: "required", Message: "is required", Value: ""}}
+
+	// as received by the client
+	var decoded jsonrpc.Error
+	data, _ := json.Marshal(b.Err())
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := json.Marshal(jsonrpc.InvalidParamsData{Violations: expected})
+
+	for name, err := range map[string]error{
+		"server":  b.Err(),
+		"client":  decoded,
+		"wrapped": fmt.Errorf("call: %w", decoded),
+		"raw":     jsonrpc.Error{Code: jsonrpc.InvalidParamsError, Data: json.RawMessage(raw)},
+	} {
+		got, ok := jsonrpc.InvalidParamsViolations(err)
+		if !ok || !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, got)
+		}
+	}
+
+	for name, err := range map[string]error{
+		"nil":     nil,
+		"other":   errors.New("invalid params"),
+		"code":    jsonrpc.Error{Code: jsonrpc.InternalError, Data: jsonrpc.InvalidParamsData{}},
+		"no data": jsonrpc.NewInvalidParamsError("field missing"),
+		"data":    jsonrpc.Error{Code: jsonrpc.InvalidParamsError, Data: "field missing"},
+	} {
+		if got, ok := jsonrpc.InvalidParamsViolations(err); ok {
+			t.Errorf("%s: expected no violations, got %v", name, got)
+		}
+	}
+}
+
+func TestErrorCause(t *testing.T) {
+	cause := fmt.Errorf("query users: %w", io.EOF)
+	err := error(jsonrpc.WrapError(jsonrpc.InternalError, cause))
+
+	if !errors.Is(err, io.EOF) {
+		t.Error("Expected error to wrap io.EOF")
+	}
+	if !errors.Is(err, jsonrpc.NewError(jsonrpc.InternalError)) {
+		t.Error("Expected error to be InternalError")
+	}
+	if errors.Is(err, jsonrpc.NewTimeoutError()) {
+		t.Error("Expected error not to be TimeoutError")
+	}
+
+	var e jsonrpc.Error
+	if !errors.As(fmt.Errorf("handler: %w", err), &e) || e.Code != jsonrpc.InternalError {
+		t.Errorf("Expected InternalError, got %v", e)
+	}
+	if got, expect := e.Message, "Internal JSON-RPC error"; got != expect {
+		t.Errorf("Expected message %q, got %q", expect, got)
+	}
+
+	b, _ := json.Marshal(e)
+	if got, expect := string(b), `{"code":-32603,"message":"Internal JSON-RPC error"}`; got != expect {
+		t.Errorf("Expected %s, got %s", expect, got)
+	}
+
+	if jsonrpc.NewError(jsonrpc.InternalError).WithCause(nil).Unwrap() != nil {
+		t.Error("Expected no cause")
+	}
+}
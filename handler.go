@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
 )
 
 // HandlerRequestFunc may take information from an header and put it into a
@@ -31,6 +33,11 @@ type Handler struct {
 	before []HandlerRequestFunc
 	after  []HandlerResponseFunc
 	logger log.Logger
+	doc    MethodDoc
+
+	validator *Validator
+
+	duration metrics.Histogram
 }
 
 // NewHandler constructs a new handler, which implements jsonrcp.Handlerer and wraps
@@ -46,6 +53,8 @@ func NewHandler(
 		dec:    dec,
 		enc:    enc,
 		logger: log.NewNopLogger(),
+
+		validator: DefaultValidator,
 	}
 	for _, option := range options {
 		option(s)
@@ -82,13 +91,20 @@ func (s Handler) ServeJSONRPC(ctx context.Context, requestHeader http.Header, pa
 		ctx = f(ctx, requestHeader)
 	}
 
+	begin := time.Now()
 	request, err := s.dec(ctx, params)
+	if err == nil && s.validator != nil {
+		err = s.validator.Validate(request)
+	}
+	observePhase(ctx, s.duration, "decode", begin)
 	if err != nil {
 		s.logger.Log("err", err)
 		return nil, nil, err
 	}
 
+	begin = time.Now()
 	response, err := s.e(ctx, request)
+	observePhase(ctx, s.duration, "endpoint", begin)
 	if err != nil {
 		s.logger.Log("err", err)
 		return nil, nil, err
@@ -100,7 +116,9 @@ func (s Handler) ServeJSONRPC(ctx context.Context, requestHeader http.Header, pa
 	}
 
 	// Encode the response from the Endpoint
+	begin = time.Now()
 	responseParams, err = s.enc(ctx, response)
+	observePhase(ctx, s.duration, "encode", begin)
 	if err != nil {
 		s.logger.Log("err", err)
 		return nil, nil, err
@@ -0,0 +1,221 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+const (
+	// HeaderSignature carries the hex encoded HMAC-SHA256 of the request
+	HeaderSignature = "X-Signature"
+
+	// HeaderSignatureKeyID identifies the key the request was signed with
+	HeaderSignatureKeyID = "X-Signature-Key-Id"
+
+	// HeaderSignatureTimestamp carries the Unix time the request was signed at
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+
+	// HeaderSignatureNonce carries the unique value of the signed request
+	HeaderSignatureNonce = "X-Signature-Nonce"
+)
+
+// HMACKeyFunc returns the secret and the Principal of the key ID. It returns
+// an error if the key is unknown.
+type HMACKeyFunc func(ctx context.Context, keyID string) (secret []byte, principal *Principal, err error)
+
+// HMACOption sets an optional parameter for HMACAuthenticator
+type HMACOption func(*hmacAuthenticator)
+
+// HMACMaxSkew sets how old or how far in the future the signature timestamp
+// may be. By default, five minutes.
+func HMACMaxSkew(d time.Duration) HMACOption {
+	return func(a *hmacAuthenticator) { a.maxSkew = d }
+}
+
+// HMACNonceCache sets the cache used to reject replayed requests. By default,
+// an in memory NonceCache is used, which is not shared between processes.
+func HMACNonceCache(c NonceCache) HMACOption {
+	return func(a *hmacAuthenticator) { a.nonces = c }
+}
+
+// HMACAuthenticator verifies the HMAC-SHA256 signature of the raw request
+// body, the timestamp and the nonce headers. Requests with stale timestamp or
+// seen nonce are rejected. Requests without HeaderSignatureKeyID are left to
+// other authenticators.
+func HMACAuthenticator(keys HMACKeyFunc, options ...HMACOption) Authenticator {
+	a := &hmacAuthenticator{
+		keys:    keys,
+		maxSkew: 5 * time.Minute,
+		nonces:  NewMemoryNonceCache(),
+		now:     time.Now,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+type hmacAuthenticator struct {
+	keys    HMACKeyFunc
+	maxSkew time.Duration
+	nonces  NonceCache
+	now     func() time.Time
+}
+
+// Authenticate implements Authenticator
+func (a *hmacAuthenticator) Authenticate(ctx context.Context, requestHeader http.Header) (*Principal, error) {
+	keyID := requestHeader.Get(HeaderSignatureKeyID)
+	if keyID == "" {
+		return nil, nil
+	}
+
+	body, ok := RawRequestFromContext(ctx)
+	if !ok {
+		return nil, NewUnauthorizedError("Request body is not available")
+	}
+
+	ts, err := strconv.ParseInt(requestHeader.Get(HeaderSignatureTimestamp), 10, 64)
+	if err != nil {
+		return nil, NewUnauthorizedError("Invalid signature timestamp")
+	}
+	signedAt := time.Unix(ts, 0)
+	now := a.now()
+	if signedAt.Before(now.Add(-a.maxSkew)) || signedAt.After(now.Add(a.maxSkew)) {
+		return nil, NewUnauthorizedError("Stale request signature")
+	}
+
+	nonce := requestHeader.Get(HeaderSignatureNonce)
+	if nonce == "" {
+		return nil, NewUnauthorizedError("Missing signature nonce")
+	}
+
+	signature, err := hex.DecodeString(requestHeader.Get(HeaderSignature))
+	if err != nil {
+		return nil, NewUnauthorizedError("Invalid request signature")
+	}
+
+	secret, principal, err := a.keys(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(signature, computeSignature(secret, ts, nonce, body)) {
+		return nil, NewUnauthorizedError("Invalid request signature")
+	}
+
+	// the nonce is remembered only for valid signatures, so it cannot be
+	// burned by a forged request
+	if !a.nonces.Add(keyID+":"+nonce, signedAt.Add(a.maxSkew)) {
+		return nil, NewUnauthorizedError("Replayed request")
+	}
+
+	if principal == nil {
+		principal = &Principal{Subject: keyID}
+	}
+	return principal, nil
+}
+
+// SignRequest signs the body of the outgoing request with the secret and sets
+// the signature headers verified by HMACAuthenticator.
+func SignRequest(r *http.Request, keyID string, secret []byte) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	nonce := hex.EncodeToString(b)
+	ts := time.Now().Unix()
+
+	r.Header.Set(HeaderSignatureKeyID, keyID)
+	r.Header.Set(HeaderSignatureTimestamp, strconv.FormatInt(ts, 10))
+	r.Header.Set(HeaderSignatureNonce, nonce)
+	r.Header.Set(HeaderSignature, hex.EncodeToString(computeSignature(secret, ts, nonce, body)))
+	return nil
+}
+
+// HMACSigner returns a RequestFunc for go-kit HTTP client which signs the
+// outgoing requests with SignRequest. The request is sent unsigned if the
+// body cannot be read.
+func HMACSigner(keyID string, secret []byte) httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		SignRequest(r, keyID, secret)
+		return ctx
+	}
+}
+
+// computeSignature returns HMAC-SHA256 of the timestamp, nonce and body
+// separated by new lines
+func computeSignature(secret []byte, ts int64, nonce string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// NonceCache remembers nonces until they expire
+type NonceCache interface {
+	// Add stores the nonce until it expires. It returns false if the nonce is
+	// already stored.
+	Add(nonce string, expires time.Time) bool
+}
+
+// NewMemoryNonceCache returns NonceCache storing the nonces in memory
+func NewMemoryNonceCache() NonceCache {
+	return &memoryNonceCache{
+		nonces: map[string]time.Time{},
+		now:    time.Now,
+	}
+}
+
+type memoryNonceCache struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	pruned time.Time
+	now    func() time.Time
+}
+
+// Add implements NonceCache
+func (c *memoryNonceCache) Add(nonce string, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.pruned) > time.Minute {
+		for n, exp := range c.nonces {
+			if now.After(exp) {
+				delete(c.nonces, n)
+			}
+		}
+		c.pruned = now
+	}
+
+	if exp, ok := c.nonces[nonce]; ok && !now.After(exp) {
+		return false
+	}
+	c.nonces[nonce] = expires
+	return true
+}
@@ -0,0 +1,123 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func TestHMACAuthenticator(t *testing.T) {
+	secret := []byte("s3cr3t")
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)},
+		jsonrpc.ServerAuthenticator(jsonrpc.HMACAuthenticator(func(_ context.Context, keyID string) ([]byte, *jsonrpc.Principal, error) {
+			if keyID != "partner" {
+				return nil, nil, errors.New("unknown key")
+			}
+			return secret, nil, nil
+		})),
+	)
+
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)
+	signed, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if err := jsonrpc.SignRequest(signed, "partner", secret); err != nil {
+		t.Fatalf("Unexpected error signing request %s", err)
+	}
+
+	send := func(body string, header http.Header) string {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header = header
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+		return rw.Body.String()
+	}
+
+	withHeader := func(name, value string) http.Header {
+		h := http.Header{}
+		for k, v := range signed.Header {
+			h[k] = v
+		}
+		h.Set(name, value)
+		return h
+	}
+
+	unauthorized := func(msg string) string {
+		return fmt.Sprintf(`"error":{"code":%d,"message":"%s"}`, jsonrpc.UnauthorizedError, msg)
+	}
+
+	cases := []struct {
+		name   string
+		body   string
+		header http.Header
+		expect string
+	}{
+		{"tampered", strings.Replace(body, `"id":1`, `"id":2`, 1), signed.Header, unauthorized("Invalid request signature")},
+		{"unknown key", body, withHeader(jsonrpc.HeaderSignatureKeyID, "other"), unauthorized("Unauthorized")},
+		{"stale", body, withHeader(jsonrpc.HeaderSignatureTimestamp, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)), unauthorized("Stale request signature")},
+		{"valid", body, signed.Header, `"result":null`},
+		{"replayed", body, signed.Header, unauthorized("Replayed request")},
+		{"unsigned", body, http.Header{}, unauthorized("Unauthorized")},
+	}
+
+	for _, c := range cases {
+		if got := send(c.body, c.header); !strings.Contains(got, c.expect) {
+			t.Errorf("TC(%s) Expected %s, got %s", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestHMACSignerKeepsBody(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("body"))
+	jsonrpc.HMACSigner("partner", []byte("s3cr3t"))(context.Background(), r)
+
+	if r.Header.Get(jsonrpc.HeaderSignature) == "" {
+		t.Error("Expected signature header")
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	if got, expect := string(body), "body"; got != expect {
+		t.Errorf("Expected body %s, got %s", expect, got)
+	}
+}
+
+func TestHMACAuthenticatorBatch(t *testing.T) {
+	secret := []byte("s3cr3t")
+	var calls int
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)},
+		jsonrpc.ServerAuthenticator(jsonrpc.HMACAuthenticator(func(_ context.Context, keyID string) ([]byte, *jsonrpc.Principal, error) {
+			calls++
+			return secret, nil, nil
+		})),
+	)
+
+	body := fmt.Sprintf(`[{"jsonrpc":"2.0","method":"%[1]s","id":1},{"jsonrpc":"2.0","method":"%[1]s","id":2},{"jsonrpc":"2.0","method":"%[1]s","id":3}]`, testMethodName)
+	send := func() string {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if err := jsonrpc.SignRequest(r, "partner", secret); err != nil {
+			t.Fatalf("Unexpected error signing request %s", err)
+		}
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+		return strings.TrimSpace(rw.Body.String())
+	}
+
+	expect := `[{"jsonrpc":"2.0","result":null,"id":1},{"jsonrpc":"2.0","result":null,"id":2},{"jsonrpc":"2.0","result":null,"id":3}]`
+	for i := 0; i < 2; i++ {
+		if got := send(); got != expect {
+			t.Errorf("%d: expected %s, got %s", i, expect, got)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected the request to be authenticated once per HTTP request, got %d calls", calls)
+	}
+}
@@ -0,0 +1,103 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// Metrics holds the instruments recorded by ServerMetrics, MetricsMiddleware
+// and HandlerMetrics. Any of them may be nil.
+type Metrics struct {
+	// Requests counts the calls, labeled by method.
+	Requests metrics.Counter
+
+	// Errors counts the failed calls, labeled by method and code.
+	Errors metrics.Counter
+
+	// Duration observes the seconds spent serving the calls, labeled by
+	// method and phase. The phase is "total" for the whole call, "decode",
+	// "endpoint" and "encode" for the phases of Handler.
+	Duration metrics.Histogram
+
+	// InFlight is the number of calls in progress, labeled by method.
+	InFlight metrics.Gauge
+
+	// BatchSize observes the number of requests in batches.
+	BatchSize metrics.Histogram
+
+	// NotificationQueue is the number of notifications in progress.
+	NotificationQueue metrics.Gauge
+}
+
+// ServerMetrics records the Metrics of every call served by the Server, the
+// sizes of batches and the number of notifications in progress.
+func ServerMetrics(m Metrics) ServerOption {
+	return func(s *Server) { s.metrics = &m }
+}
+
+// HandlerMetrics records the duration of the decode, endpoint and encode
+// phases of the Handler.
+func HandlerMetrics(m Metrics) HandlerOption {
+	return func(s *Handler) { s.duration = m.Duration }
+}
+
+// MetricsMiddleware records the requests, errors, duration and in flight
+// calls of the next Handlerer. The method label is taken from the context.
+func MetricsMiddleware(m Metrics) HandlerMiddleware {
+	return func(next Handlerer) Handlerer {
+		return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (response json.RawMessage, responseHeader http.Header, err error) {
+			method, _ := MethodFromContext(ctx)
+
+			if m.Requests != nil {
+				m.Requests.With("method", method).Add(1)
+			}
+			if m.InFlight != nil {
+				m.InFlight.With("method", method).Add(1)
+				defer m.InFlight.With("method", method).Add(-1)
+			}
+
+			defer func(begin time.Time) {
+				if m.Duration != nil {
+					m.Duration.With("method", method, "phase", "total").Observe(time.Since(begin).Seconds())
+				}
+				if m.Errors == nil {
+					return
+				}
+
+				code := 0
+				if err != nil {
+					code = errorResponse(ctx, err).Error.Code
+				} else if e, ok := responseError(response); ok {
+					code = e.Code
+				}
+				if code != 0 {
+					m.Errors.With("method", method, "code", strconv.Itoa(code)).Add(1)
+				}
+			}(time.Now())
+
+			return next.ServeJSONRPC(ctx, requestHeader, params)
+		})
+	}
+}
+
+// metricsMiddleware applies MetricsMiddleware if ServerMetrics is set
+func (s *Server) metricsMiddleware(next Handlerer) Handlerer {
+	if s.metrics == nil {
+		return next
+	}
+	return MetricsMiddleware(*s.metrics)(next)
+}
+
+// observePhase records the duration of the Handler phase
+func observePhase(ctx context.Context, h metrics.Histogram, phase string, begin time.Time) {
+	if h == nil {
+		return
+	}
+	method, _ := MethodFromContext(ctx)
+	h.With("method", method, "phase", phase).Observe(time.Since(begin).Seconds())
+}
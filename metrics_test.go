@@ -0,0 +1,171 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+	"github.com/go-kit/kit/metrics"
+)
+
+// recorder implements metrics.Counter, metrics.Gauge and metrics.Histogram,
+// it sums the values and counts the observations by label values.
+type recorder struct {
+	mu     sync.Mutex
+	lvs    []string
+	values map[string]float64
+	counts map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{values: map[string]float64{}, counts: map[string]int{}}
+}
+
+func (r *recorder) with(labelValues ...string) *recorder {
+	return &recorder{
+		lvs:    append(append([]string{}, r.lvs...), labelValues...),
+		values: r.values,
+		counts: r.counts,
+	}
+}
+
+func (r *recorder) record(value float64, set bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.Join(r.lvs, ",")
+	if set {
+		r.values[key] = value
+	} else {
+		r.values[key] += value
+	}
+	r.counts[key]++
+}
+
+func (r *recorder) value(labelValues ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[strings.Join(labelValues, ",")]
+}
+
+func (r *recorder) count(labelValues ...string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[strings.Join(labelValues, ",")]
+}
+
+type counter struct{ *recorder }
+
+func (c counter) With(labelValues ...string) metrics.Counter { return counter{c.with(labelValues...)} }
+func (c counter) Add(delta float64)                          { c.record(delta, false) }
+
+type gauge struct{ *recorder }
+
+func (g gauge) With(labelValues ...string) metrics.Gauge { return gauge{g.with(labelValues...)} }
+func (g gauge) Set(value float64)                        { g.record(value, true) }
+func (g gauge) Add(delta float64)                        { g.record(delta, false) }
+
+type histogram struct{ *recorder }
+
+func (h histogram) With(labelValues ...string) metrics.Histogram {
+	return histogram{h.with(labelValues...)}
+}
+func (h histogram) Observe(value float64) { h.record(value, false) }
+
+func TestServerMetrics(t *testing.T) {
+	var (
+		requests = newRecorder()
+		errs     = newRecorder()
+		duration = newRecorder()
+		inFlight = newRecorder()
+		batch    = newRecorder()
+		queue    = newRecorder()
+	)
+	m := jsonrpc.Metrics{
+		Requests:          counter{requests},
+		Errors:            counter{errs},
+		Duration:          histogram{duration},
+		InFlight:          gauge{inFlight},
+		BatchSize:         histogram{batch},
+		NotificationQueue: gauge{queue},
+	}
+
+	notified := make(chan struct{})
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			"add": jsonrpc.NewHandler(
+				func(_ context.Context, request interface{}) (interface{}, error) {
+					if request == nil {
+						close(notified)
+					}
+					return 3, nil
+				},
+				func(_ context.Context, params json.RawMessage) (interface{}, error) {
+					if len(params) == 0 {
+						return nil, nil
+					}
+					return params, nil
+				},
+				func(_ context.Context, response interface{}) (json.RawMessage, error) { return json.Marshal(response) },
+				jsonrpc.HandlerMetrics(m),
+			),
+			"fail": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				return nil, nil, errors.New("dang")
+			}),
+			"invalid": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				resp, _ := json.Marshal(jsonrpc.NewInvalidParamsError())
+				return resp, nil, nil
+			}),
+		},
+		jsonrpc.ServerMetrics(m),
+	)
+
+	body := `[
+		{"jsonrpc":"2.0","method":"add","params":[1,2],"id":1},
+		{"jsonrpc":"2.0","method":"fail","id":2},
+		{"jsonrpc":"2.0","method":"invalid","id":3},
+		{"jsonrpc":"2.0","method":"add"}
+	]`
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+	waitFor(t, func() bool { return queue.count() == 2 })
+
+	cases := []struct {
+		name   string
+		got    float64
+		expect float64
+	}{
+		{"requests add", requests.value("method", "add"), 2},
+		{"requests fail", requests.value("method", "fail"), 1},
+		{"errors fail", errs.value("method", "fail", "code", fmt.Sprint(jsonrpc.InternalError)), 1},
+		{"errors invalid", errs.value("method", "invalid", "code", fmt.Sprint(jsonrpc.InvalidParamsError)), 1},
+		{"errors add", float64(errs.count("method", "add", "code", "0")), 0},
+		{"in flight add", inFlight.value("method", "add"), 0},
+		{"in flight add changes", float64(inFlight.count("method", "add")), 4},
+		{"total duration fail", float64(duration.count("method", "fail", "phase", "total")), 1},
+		{"decode duration add", float64(duration.count("method", "add", "phase", "decode")), 2},
+		{"endpoint duration add", float64(duration.count("method", "add", "phase", "endpoint")), 2},
+		{"encode duration add", float64(duration.count("method", "add", "phase", "encode")), 2},
+		{"batch size", batch.value(), 4},
+		{"notification queue", queue.value(), 0},
+	}
+
+	for _, c := range cases {
+		if c.got != c.expect {
+			t.Errorf("TC(%s) Expected %v, got %v", c.name, c.expect, c.got)
+		}
+	}
+}
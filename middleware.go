@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as
+// Handlerer.
+type HandlerFunc func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (response json.RawMessage, responseHeader http.Header, err error)
+
+// ServeJSONRPC implements Handlerer
+func (f HandlerFunc) ServeJSONRPC(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+	return f(ctx, requestHeader, params)
+}
+
+// HandlerMiddleware is a chainable behavior modifier for Handlerer. The method
+// name and the RequestID of the call are available from the context with
+// MethodFromContext and RequestIDFromContext.
+type HandlerMiddleware func(Handlerer) Handlerer
+
+// Chain is a helper function for composing middlewares. Requests will
+// traverse them in the order they're declared. That is, the first middleware
+// is treated as the outermost middleware.
+func Chain(outer HandlerMiddleware, others ...HandlerMiddleware) HandlerMiddleware {
+	return func(next Handlerer) Handlerer {
+		for i := len(others) - 1; i >= 0; i-- {
+			next = others[i](next)
+		}
+		return outer(next)
+	}
+}
+
+// ServerHandlerMiddleware applies the middlewares to every handler served by
+// the Server. The first middleware is the outermost one.
+func ServerHandlerMiddleware(middlewares ...HandlerMiddleware) ServerOption {
+	return func(s *Server) { s.middlewares = append(s.middlewares, middlewares...) }
+}
+
+// wrapHandlers returns a copy of the handlers with the middlewares applied.
+// The wrapped handlers keep the MethodDoc of the original ones.
+func wrapHandlers(sh Handlers, middlewares []HandlerMiddleware) Handlers {
+	if len(middlewares) == 0 {
+		return sh
+	}
+
+	mw := Chain(middlewares[0], middlewares[1:]...)
+	wrapped := make(Handlers, len(sh))
+	for method, h := range sh {
+		wrapped[method] = mw(h)
+		if d, ok := h.(MethodDescriber); ok {
+			wrapped[method] = Describe(wrapped[method], d.MethodDoc())
+		}
+	}
+	return wrapped
+}
@@ -0,0 +1,115 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func annotate(name string, calls *[]string) jsonrpc.HandlerMiddleware {
+	return func(next jsonrpc.Handlerer) jsonrpc.Handlerer {
+		return jsonrpc.HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+			*calls = append(*calls, name)
+			return next.ServeJSONRPC(ctx, requestHeader, params)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+
+	h := jsonrpc.Chain(
+		annotate("first", &calls),
+		annotate("second", &calls),
+		annotate("third", &calls),
+	)(jsonrpc.HandlerFunc(nopHandler))
+
+	h.ServeJSONRPC(context.Background(), http.Header{}, nil)
+
+	if got, expect := strings.Join(calls, ","), "first,second,third"; got != expect {
+		t.Errorf("got %s, expect %s", got, expect)
+	}
+}
+
+func TestServerHandlerMiddleware(t *testing.T) {
+	var calls []string
+
+	inspect := func(next jsonrpc.Handlerer) jsonrpc.Handlerer {
+		return jsonrpc.HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+			if got, expect := ctx.Value(jsonrpc.ContextKeyRequestMethod), testMethodName; got != expect {
+				t.Errorf("Expect method %v, got %v", expect, got)
+			}
+			id, _ := ctx.Value(jsonrpc.ContextKeyRequestID).(*jsonrpc.RequestID)
+			if id == nil {
+				t.Fatal("Expect request id in the context")
+			}
+			if got, _ := id.Int(); got != 1 {
+				t.Errorf("Expect request id 1, got %d", got)
+			}
+
+			resp, hdr, err := next.ServeJSONRPC(ctx, requestHeader, params)
+			if got, expect := string(resp), `"woohoo"`; got != expect {
+				t.Errorf("Expect result %s, got %s", expect, got)
+			}
+			return resp, hdr, err
+		})
+	}
+
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				return json.RawMessage(`"woohoo"`), nil, nil
+			}),
+		},
+		jsonrpc.ServerHandlerMiddleware(annotate("outer", &calls), inspect),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if got, expect := strings.Join(calls, ","), "outer"; got != expect {
+		t.Errorf("got %s, expect %s", got, expect)
+	}
+
+	if got, expect := strings.TrimSpace(rw.Body.String()), `{"jsonrpc":"2.0","result":"woohoo","id":1}`; got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+}
+
+func TestServerHandlerMiddlewareShortCircuit(t *testing.T) {
+	deny := func(jsonrpc.Handlerer) jsonrpc.Handlerer {
+		return jsonrpc.HandlerFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			return nil, nil, jsonrpc.NewError(-32000, "denied")
+		})
+	}
+
+	var called bool
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				called = true
+				return nil, nil, nil
+			}),
+		},
+		jsonrpc.ServerHandlerMiddleware(deny),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if called {
+		t.Error("Expected handler not to be called")
+	}
+
+	if got, expect := strings.TrimSpace(rw.Body.String()), `{"jsonrpc":"2.0","error":{"code":-32000,"message":"denied"},"id":1}`; got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+}
@@ -0,0 +1,306 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// OpenRPCVersion is the version of the OpenRPC specification of the generated
+// documents
+const OpenRPCVersion = "1.2.6"
+
+// MethodDoc describes a method in the generated OpenRPC document
+type MethodDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Params is the schema of the params. An object schema describes params
+	// by-name, an array schema with PrefixItems describes params by-position.
+	Params *Schema
+
+	// Result is the schema of the result
+	Result *Schema
+
+	// Errors are the application errors returned by the method
+	Errors []Error
+
+	Examples   []MethodExample
+	Deprecated bool
+}
+
+// MethodExample is an example call of the method. Params and Result are
+// encoded as JSON.
+type MethodExample struct {
+	Name    string
+	Summary string
+	Params  interface{}
+	Result  interface{}
+}
+
+// MethodDescriber is implemented by the handlers which describe their method
+type MethodDescriber interface {
+	MethodDoc() MethodDoc
+}
+
+// HandlerDoc sets the description of the method served by the Handler
+func HandlerDoc(doc MethodDoc) HandlerOption {
+	return func(s *Handler) { s.doc = doc }
+}
+
+// MethodDoc implements MethodDescriber
+func (s Handler) MethodDoc() MethodDoc {
+	return s.doc
+}
+
+// Describe returns the Handlerer described by the doc
+func Describe(h Handlerer, doc MethodDoc) Handlerer {
+	return describedHandler{h, doc}
+}
+
+type describedHandler struct {
+	Handlerer
+	doc MethodDoc
+}
+
+func (h describedHandler) MethodDoc() MethodDoc {
+	return h.doc
+}
+
+// Doc returns the description of the method, false if the handler does not
+// implement MethodDescriber
+func (h Handlers) Doc(method string) (MethodDoc, bool) {
+	d, ok := h[method].(MethodDescriber)
+	if !ok {
+		return MethodDoc{}, false
+	}
+	return d.MethodDoc(), true
+}
+
+// OpenRPCDocument is the OpenRPC 1.x document https://spec.open-rpc.org
+type OpenRPCDocument struct {
+	OpenRPC string          `json:"openrpc"`
+	Info    OpenRPCInfo     `json:"info"`
+	Servers []OpenRPCServer `json:"servers,omitempty"`
+	Methods []OpenRPCMethod `json:"methods"`
+
+	Components *OpenRPCComponents `json:"components,omitempty"`
+}
+
+// OpenRPCComponents holds the reusable objects of the document
+type OpenRPCComponents struct {
+	// Errors are the registered error codes by their name, see RegisterError
+	Errors map[string]OpenRPCError `json:"errors,omitempty"`
+}
+
+// OpenRPCError is the error object of the components, the description is the
+// x-description specification extension
+type OpenRPCError struct {
+	Code        int    `json:"code"`
+	Message     string `json:"message"`
+	Description string `json:"x-description,omitempty"`
+}
+
+// OpenRPCInfo provides metadata about the API
+type OpenRPCInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenRPCServer is a server providing the API
+type OpenRPCServer struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+// OpenRPCMethod describes a method of the API
+type OpenRPCMethod struct {
+	Name           string                  `json:"name"`
+	Summary        string                  `json:"summary,omitempty"`
+	Description    string                  `json:"description,omitempty"`
+	Tags           []OpenRPCTag            `json:"tags,omitempty"`
+	ParamStructure string                  `json:"paramStructure,omitempty"`
+	Params         []ContentDescriptor     `json:"params"`
+	Result         *ContentDescriptor      `json:"result,omitempty"`
+	Deprecated     bool                    `json:"deprecated,omitempty"`
+	Errors         []Error                 `json:"errors,omitempty"`
+	Examples       []OpenRPCExamplePairing `json:"examples,omitempty"`
+}
+
+// OpenRPCTag groups the methods
+type OpenRPCTag struct {
+	Name string `json:"name"`
+}
+
+// ContentDescriptor describes a param or the result of a method
+type ContentDescriptor struct {
+	Name        string  `json:"name"`
+	Summary     string  `json:"summary,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+}
+
+// OpenRPCExamplePairing is an example call of a method
+type OpenRPCExamplePairing struct {
+	Name    string           `json:"name"`
+	Summary string           `json:"summary,omitempty"`
+	Params  []OpenRPCExample `json:"params"`
+	Result  *OpenRPCExample  `json:"result,omitempty"`
+}
+
+// OpenRPCExample is an example value
+type OpenRPCExample struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// NewOpenRPCDocument generates the OpenRPC document of the handlers sorted by
+// the method name. Methods without MethodDoc are listed with any params and
+// result. The components of the document list the registered error codes, see
+// RegisteredErrors.
+func NewOpenRPCDocument(info OpenRPCInfo, handlers Handlers, servers ...OpenRPCServer) OpenRPCDocument {
+	doc := OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    info,
+		Servers: servers,
+		Methods: []OpenRPCMethod{},
+	}
+
+	methods := make([]string, 0, len(handlers))
+	for method := range handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		md, _ := handlers.Doc(method)
+		doc.Methods = append(doc.Methods, openRPCMethod(method, md))
+	}
+
+	doc.Components = &OpenRPCComponents{Errors: map[string]OpenRPCError{}}
+	for _, d := range RegisteredErrors() {
+		name := d.Name
+		if name == "" {
+			name = "Error" + strconv.Itoa(d.Code)
+		}
+		doc.Components.Errors[name] = OpenRPCError{Code: d.Code, Message: d.Message, Description: d.Description}
+	}
+	return doc
+}
+
+func openRPCMethod(name string, md MethodDoc) OpenRPCMethod {
+	m := OpenRPCMethod{
+		Name:           name,
+		Summary:        md.Summary,
+		Description:    md.Description,
+		ParamStructure: "either",
+		Params:         []ContentDescriptor{},
+		Result:         &ContentDescriptor{Name: "result", Schema: md.Result},
+		Deprecated:     md.Deprecated,
+		Errors:         md.Errors,
+	}
+	if m.Result.Schema == nil {
+		m.Result.Schema = &Schema{}
+	}
+	for _, tag := range md.Tags {
+		m.Tags = append(m.Tags, OpenRPCTag{Name: tag})
+	}
+
+	if p := md.Params; p != nil {
+		switch {
+		case p.Type == "object":
+			m.ParamStructure = "by-name"
+			names := make([]string, 0, len(p.Properties))
+			for name := range p.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				m.Params = append(m.Params, contentDescriptor(name, p.Properties[name], contains(p.Required, name)))
+			}
+		case p.Type == "array" && len(p.PrefixItems) > 0:
+			m.ParamStructure = "by-position"
+			minItems := len(p.PrefixItems)
+			if p.MinItems != nil {
+				minItems = *p.MinItems
+			}
+			for i, item := range p.PrefixItems {
+				name := item.Title
+				if name == "" {
+					name = "param" + strconv.Itoa(i)
+				}
+				m.Params = append(m.Params, contentDescriptor(name, item, i < minItems))
+			}
+		}
+	}
+
+	for _, e := range md.Examples {
+		m.Examples = append(m.Examples, openRPCExample(e, m.Params))
+	}
+	return m
+}
+
+func contentDescriptor(name string, s *Schema, required bool) ContentDescriptor {
+	return ContentDescriptor{
+		Name:        name,
+		Description: s.Description,
+		Required:    required,
+		Schema:      s,
+		Deprecated:  s.Deprecated,
+	}
+}
+
+// openRPCExample splits the params of the example into the values of the
+// params of the method
+func openRPCExample(e MethodExample, params []ContentDescriptor) OpenRPCExamplePairing {
+	p := OpenRPCExamplePairing{
+		Name:    e.Name,
+		Summary: e.Summary,
+		Params:  []OpenRPCExample{},
+	}
+	if e.Result != nil {
+		p.Result = &OpenRPCExample{Name: "result", Value: e.Result}
+	}
+
+	b, err := json.Marshal(e.Params)
+	if err != nil {
+		return p
+	}
+
+	var named map[string]json.RawMessage
+	var positional []json.RawMessage
+	switch {
+	case json.Unmarshal(b, &named) == nil:
+		names := make([]string, 0, len(named))
+		for name := range named {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p.Params = append(p.Params, OpenRPCExample{Name: name, Value: named[name]})
+		}
+	case json.Unmarshal(b, &positional) == nil:
+		for i, v := range positional {
+			name := "param" + strconv.Itoa(i)
+			if i < len(params) {
+				name = params[i].Name
+			}
+			p.Params = append(p.Params, OpenRPCExample{Name: name, Value: v})
+		}
+	}
+	return p
+}
+
+// OpenRPCHandler serves the OpenRPC document of the handlers over HTTP GET
+func OpenRPCHandler(info OpenRPCInfo, handlers Handlers, servers ...OpenRPCServer) http.Handler {
+	doc := NewOpenRPCDocument(info, handlers, servers...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		json.NewEncoder(w).Encode(doc)
+	})
+}
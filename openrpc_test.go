@@ -0,0 +1,101 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type addRequest struct {
+	A int `json:"a" description:"first operand" validate:"required"`
+	B int `json:"b,omitempty"`
+}
+
+func TestNewOpenRPCDocument(t *testing.T) {
+	handlers := jsonrpc.NewHandlers(jsonrpc.EndpointCodecMap{
+		"add": {
+			Endpoint: func(context.Context, interface{}) (interface{}, error) { return 0, nil },
+			Decode:   func(context.Context, json.RawMessage) (interface{}, error) { return nil, nil },
+			Encode:   func(context.Context, interface{}) (json.RawMessage, error) { return nil, nil },
+			Doc: jsonrpc.MethodDoc{
+				Summary: "Adds two numbers",
+				Tags:    []string{"math"},
+				Errors:  []jsonrpc.Error{{Code: 100, Message: "Overflow"}},
+				Examples: []jsonrpc.MethodExample{
+					{Name: "small", Params: addRequest{A: 1, B: 2}, Result: 3},
+				},
+			},
+			Request:  addRequest{},
+			Response: 0,
+		},
+	}, nil)
+	handlers.Set("concat", jsonrpc.Describe(HandlererFunc(nopHandler), jsonrpc.MethodDoc{
+		Params: &jsonrpc.Schema{
+			Type:        "array",
+			PrefixItems: []*jsonrpc.Schema{{Title: "left", Type: "string"}, {Type: "string"}},
+			MinItems:    intPtr(1),
+		},
+		Deprecated: true,
+		Examples: []jsonrpc.MethodExample{
+			{Name: "positional", Params: []string{"a", "b"}},
+		},
+	}))
+	handlers.Set("ping", HandlererFunc(nopHandler))
+
+	doc := jsonrpc.NewOpenRPCDocument(jsonrpc.OpenRPCInfo{Title: "Calculator", Version: "1.0.0"}, handlers)
+	if e, ok := doc.Components.Errors["InvalidParamsError"]; !ok || e.Code != jsonrpc.InvalidParamsError {
+		t.Errorf("Expected InvalidParamsError in the components, got %v", doc.Components)
+	}
+	doc.Components = nil
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"openrpc":"1.2.6","info":{"title":"Calculator","version":"1.0.0"},"methods":[` +
+		`{"name":"add","summary":"Adds two numbers","tags":[{"name":"math"}],"paramStructure":"by-name","params":[` +
+		`{"name":"a","description":"first operand","required":true,"schema":{"description":"first operand","type":"integer"}},` +
+		`{"name":"b","schema":{"type":"integer"}}],` +
+		`"result":{"name":"result","schema":{"type":"integer"}},` +
+		`"errors":[{"code":100,"message":"Overflow"}],` +
+		`"examples":[{"name":"small","params":[{"name":"a","value":1},{"name":"b","value":2}],"result":{"name":"result","value":3}}]},` +
+		`{"name":"concat","paramStructure":"by-position","params":[` +
+		`{"name":"left","required":true,"schema":{"title":"left","type":"string"}},` +
+		`{"name":"param1","schema":{"type":"string"}}],` +
+		`"result":{"name":"result","schema":{}},"deprecated":true,` +
+		`"examples":[{"name":"positional","params":[{"name":"left","value":"a"},{"name":"param1","value":"b"}]}]},` +
+		`{"name":"ping","paramStructure":"either","params":[],"result":{"name":"result","schema":{}}}]}`
+	if got := string(b); got != expect {
+		t.Errorf("Expected\n%s\ngot\n%s", expect, got)
+	}
+}
+
+func TestOpenRPCHandler(t *testing.T) {
+	h := jsonrpc.OpenRPCHandler(
+		jsonrpc.OpenRPCInfo{Title: "API", Version: "1"},
+		jsonrpc.Handlers{"ping": HandlererFunc(nopHandler)},
+		jsonrpc.OpenRPCServer{URL: "http://localhost/rpc"},
+	)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openrpc.json", nil))
+
+	var doc jsonrpc.OpenRPCDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Methods) != 1 || doc.Methods[0].Name != "ping" {
+		t.Errorf("Expected ping method, got %+v", doc.Methods)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "http://localhost/rpc" {
+		t.Errorf("Expected server, got %+v", doc.Servers)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
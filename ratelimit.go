@@ -0,0 +1,167 @@
+package jsonrpc
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// ClientIdentityFunc returns the identity of the client the rate limits are
+// applied to.
+type ClientIdentityFunc func(ctx context.Context, requestHeader http.Header) string
+
+// RemoteAddrIdentity identifies the client by the host of the remote address
+// of the HTTP request.
+func RemoteAddrIdentity(ctx context.Context, _ http.Header) string {
+	addr, _ := ctx.Value(httptransport.ContextKeyRequestRemoteAddr).(string)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// HeaderIdentity identifies the client by the value of the request header,
+// e.g. an API key.
+func HeaderIdentity(name string) ClientIdentityFunc {
+	return func(_ context.Context, requestHeader http.Header) string {
+		return requestHeader.Get(name)
+	}
+}
+
+// RateLimit configures a token bucket
+type RateLimit struct {
+	// Rate is the number of calls per second added to the bucket.
+	Rate float64
+
+	// Burst is the size of the bucket.
+	Burst int
+}
+
+// ServerRateLimit limits the rate of calls of every method per client. The
+// client is identified by the identity function, by default
+// RemoteAddrIdentity. Every call counts, including the elements of a batch.
+// Limited calls get RateLimitedError and the Retry-After header.
+func ServerRateLimit(identity ClientIdentityFunc, limit RateLimit) ServerOption {
+	return func(s *Server) {
+		rl := s.ensureRateLimiter()
+		if identity != nil {
+			rl.identity = identity
+		}
+		rl.limit = &limit
+	}
+}
+
+// ServerMethodRateLimit limits the rate of calls of the method per client,
+// overriding the limit set by ServerRateLimit.
+func ServerMethodRateLimit(method string, limit RateLimit) ServerOption {
+	return func(s *Server) {
+		s.ensureRateLimiter().methods[method] = limit
+	}
+}
+
+func (s *Server) ensureRateLimiter() *rateLimiter {
+	if s.rateLimiter == nil {
+		s.rateLimiter = &rateLimiter{
+			identity: RemoteAddrIdentity,
+			methods:  map[string]RateLimit{},
+			buckets:  map[rateLimitKey]*list.Element{},
+			now:      time.Now,
+		}
+	}
+	return s.rateLimiter
+}
+
+// rateLimitMiddleware rejects the calls exceeding the rate limit
+func (s *Server) rateLimitMiddleware(next Handlerer) Handlerer {
+	rl := s.rateLimiter
+	if rl == nil {
+		return next
+	}
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		method, _ := MethodFromContext(ctx)
+		if ok, retryAfter := rl.allow(ctx, requestHeader, method); !ok {
+			return nil, nil, withRetryAfter(NewRateLimitedError(retryAfter))
+		}
+		return next.ServeJSONRPC(ctx, requestHeader, params)
+	})
+}
+
+// maxBuckets is the number of buckets kept, the least recently used ones are
+// dropped, so clients rotating their identities cannot exhaust the memory.
+const maxBuckets = 10000
+
+type rateLimitKey struct {
+	client string
+	method string
+}
+
+type tokenBucket struct {
+	key    rateLimitKey
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	identity ClientIdentityFunc
+	limit    *RateLimit
+	methods  map[string]RateLimit
+	now      func() time.Time
+
+	mu      sync.Mutex
+	buckets map[rateLimitKey]*list.Element
+	lru     list.List
+}
+
+// allow takes a token from the bucket of the client and method. If there is
+// none, it returns the time when the next token is available.
+func (rl *rateLimiter) allow(ctx context.Context, requestHeader http.Header, method string) (bool, time.Duration) {
+	limit, ok := rl.methods[method]
+	if !ok {
+		if rl.limit == nil {
+			return true, 0
+		}
+		limit = *rl.limit
+	}
+
+	key := rateLimitKey{client: rl.identity(ctx, requestHeader), method: method}
+	now := rl.now()
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	var b *tokenBucket
+	if e, ok := rl.buckets[key]; ok {
+		rl.lru.MoveToFront(e)
+		b = e.Value.(*tokenBucket)
+	} else {
+		if len(rl.buckets) >= maxBuckets {
+			oldest := rl.lru.Back()
+			rl.lru.Remove(oldest)
+			delete(rl.buckets, oldest.Value.(*tokenBucket).key)
+		}
+		b = &tokenBucket{key: key, tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = rl.lru.PushFront(b)
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * limit.Rate
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if limit.Rate <= 0 {
+		return false, time.Second
+	}
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
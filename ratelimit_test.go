@@ -0,0 +1,158 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+func TestServerRateLimit(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(nopHandler),
+			"other":        HandlererFunc(nopHandler),
+		},
+		jsonrpc.ServerRateLimit(jsonrpc.HeaderIdentity("X-Api-Key"), jsonrpc.RateLimit{Rate: 0.1, Burst: 2}),
+		jsonrpc.ServerMethodRateLimit("other", jsonrpc.RateLimit{Rate: 0.5, Burst: 1}),
+	)
+
+	call := func(method, key string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, method)))
+		r.Header.Set("X-Api-Key", key)
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+		return rw
+	}
+
+	limited := func(rw *httptest.ResponseRecorder) bool {
+		return strings.Contains(rw.Body.String(), fmt.Sprintf(`"code":%d`, jsonrpc.RateLimitedError))
+	}
+
+	for i := 0; i < 2; i++ {
+		if rw := call(testMethodName, "a"); limited(rw) {
+			t.Fatalf("Call %d should not be limited", i)
+		}
+	}
+
+	rw := call(testMethodName, "a")
+	if !limited(rw) {
+		t.Fatalf("Expected call to be limited, got %s", rw.Body.String())
+	}
+	if got, expect := rw.Header().Get("Retry-After"), "10"; got != expect {
+		t.Errorf("Expected Retry-After %s, got %s", expect, got)
+	}
+	if !strings.Contains(rw.Body.String(), `"data":{"retryAfter":10}`) {
+		t.Errorf("Expected retry after in data, got %s", rw.Body.String())
+	}
+
+	// other clients and methods have their own buckets
+	if rw := call(testMethodName, "b"); limited(rw) {
+		t.Error("Client b should not be limited")
+	}
+	if rw := call("other", "a"); limited(rw) {
+		t.Error("Method other should not be limited")
+	}
+	if rw := call("other", "a"); !limited(rw) {
+		t.Error("Method other should be limited")
+	} else if got, expect := rw.Header().Get("Retry-After"), "2"; got != expect {
+		t.Errorf("Expected Retry-After %s, got %s", expect, got)
+	}
+}
+
+func TestServerRateLimitBatchElements(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)},
+		jsonrpc.ServerRateLimit(nil, jsonrpc.RateLimit{Rate: 0.1, Burst: 2}),
+	)
+
+	body := fmt.Sprintf(`[{"jsonrpc":"2.0","method":"%[1]s","id":1},{"jsonrpc":"2.0","method":"%[1]s","id":2},{"jsonrpc":"2.0","method":"%[1]s","id":3}]`, testMethodName)
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	expect := `[` +
+		`{"jsonrpc":"2.0","result":null,"id":1},` +
+		`{"jsonrpc":"2.0","result":null,"id":2},` +
+		fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":%d,"message":"Too many requests","data":{"retryAfter":10}},"id":3}`, jsonrpc.RateLimitedError) +
+		`]`
+	if got := strings.TrimSpace(rw.Body.String()); got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+	if got, expect := rw.Header().Get("Retry-After"), "10"; got != expect {
+		t.Errorf("Expected Retry-After %s, got %s", expect, got)
+	}
+}
+
+func TestRemoteAddrIdentity(t *testing.T) {
+	cases := []struct {
+		addr   string
+		expect string
+	}{
+		{"127.0.0.1:1234", "127.0.0.1"},
+		{"[::1]:1234", "::1"},
+		{"unix", "unix"},
+	}
+
+	for _, c := range cases {
+		ctx := context.WithValue(context.Background(), httptransport.ContextKeyRequestRemoteAddr, c.addr)
+		if got := jsonrpc.RemoteAddrIdentity(ctx, nil); got != c.expect {
+			t.Errorf("TC(%s) Expected %s, got %s", c.addr, c.expect, got)
+		}
+	}
+}
+
+func TestServerRateLimitBatch(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(nopHandler),
+			"other":        HandlererFunc(nopHandler),
+		},
+		jsonrpc.ServerRateLimit(nil, jsonrpc.RateLimit{Rate: 0.1, Burst: 1}),
+		jsonrpc.ServerMethodRateLimit("other", jsonrpc.RateLimit{Rate: 0.05, Burst: 1}),
+	)
+
+	body := fmt.Sprintf(`[{"jsonrpc":"2.0","method":"%[1]s","id":1},{"jsonrpc":"2.0","method":"%[1]s","id":2},{"jsonrpc":"2.0","method":"%[1]s","id":3},{"jsonrpc":"2.0","method":"other","id":4},{"jsonrpc":"2.0","method":"other","id":5}]`, testMethodName)
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if got, expect := rw.Header()["Retry-After"], []string{"20"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected Retry-After %v, got %v", expect, got)
+	}
+}
+
+func TestServerRateLimitEvictsLeastRecentlyUsed(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)},
+		jsonrpc.ServerRateLimit(jsonrpc.HeaderIdentity("X-Api-Key"), jsonrpc.RateLimit{Rate: 0.001, Burst: 1}),
+	)
+
+	body := []byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName))
+	limited := func(key string) bool {
+		r, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		r.Header.Set("X-Api-Key", key)
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+		return strings.Contains(rw.Body.String(), fmt.Sprintf(`"code":%d`, jsonrpc.RateLimitedError))
+	}
+
+	limited("oldest")
+	for i := 0; i < 10000; i++ {
+		limited(strconv.Itoa(i))
+	}
+	if !limited("9999") {
+		t.Error("Expected recently used client to be limited")
+	}
+	if limited("oldest") {
+		t.Error("Expected the bucket of the least recently used client to be dropped")
+	}
+}
@@ -0,0 +1,269 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recording is a request/response pair written by Recorder as one line of
+// JSONL.
+type Recording struct {
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+
+	Header  http.Header     `json:"header,omitempty"`
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// InvalidRequest holds the body which is not valid JSON. It is
+	// Unparseable if the params are redacted, see RecorderRedactParams.
+	InvalidRequest string `json:"invalidRequest,omitempty"`
+
+	StatusCode     int             `json:"statusCode"`
+	ResponseHeader http.Header     `json:"responseHeader,omitempty"`
+	Response       json.RawMessage `json:"response,omitempty"`
+}
+
+// RecorderOption sets an optional parameter for Recorder
+type RecorderOption func(*Recorder)
+
+// RecorderRedactParams sets the paths of params values replaced by Redacted
+// in the recorded requests. See Redact for the path syntax.
+func RecorderRedactParams(paths ...string) RecorderOption {
+	return func(r *Recorder) { r.params = append(r.params, paths...) }
+}
+
+// RecorderRedactResult sets the paths of result values replaced by Redacted
+// in the recorded responses. See Redact for the path syntax. The redacted
+// results differ when replayed, pass the same paths to the -ignore flag of
+// jsonrpc-replay.
+func RecorderRedactResult(paths ...string) RecorderOption {
+	return func(r *Recorder) { r.result = append(r.result, paths...) }
+}
+
+// RecorderRedactHeaders sets the request and response headers replaced by
+// Redacted. By default, Authorization, Cookie and Set-Cookie are redacted.
+func RecorderRedactHeaders(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.headers = append(r.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// Recorder writes the requests served by the Server and their responses to
+// the writer as JSONL. Use RotatingFile to limit the size of the recording.
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	params  []string
+	result  []string
+	headers []string
+}
+
+// NewRecorder constructs a new Recorder writing to w
+func NewRecorder(w io.Writer, options ...RecorderOption) *Recorder {
+	r := &Recorder{
+		w:       w,
+		headers: []string{"Authorization", "Cookie", "Set-Cookie"},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// ServerRecorder records every POST request served by the Server
+func ServerRecorder(r *Recorder) ServerOption {
+	return func(s *Server) { s.recorder = r }
+}
+
+// Record writes the Recording
+func (r *Recorder) Record(rec Recording) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.w.Write(b)
+	return err
+}
+
+// serve serves the request by next and records it
+func (r *Recorder) serve(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) error {
+	begin := time.Now()
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		next(w, req)
+		return err
+	}
+
+	rw := &recordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+	next(rw, req)
+
+	rec := Recording{
+		Time:           begin.UTC(),
+		Duration:       time.Since(begin),
+		Header:         r.redactHeader(req.Header),
+		StatusCode:     rw.statusCode,
+		ResponseHeader: r.redactHeader(w.Header()),
+	}
+
+	switch {
+	case json.Valid(body):
+		rec.Request = r.redactMembers(body, "params.", r.params)
+	case len(r.params) > 0:
+		rec.InvalidRequest = Unparseable
+	default:
+		rec.InvalidRequest = string(body)
+	}
+
+	if resp := bytes.TrimSpace(rw.body.Bytes()); json.Valid(resp) {
+		rec.Response = r.redactMembers(resp, "result.", r.result)
+	}
+
+	return r.Record(rec)
+}
+
+// redactHeader returns copy of the header with redacted values
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for k, v := range header {
+		redacted[k] = append([]string(nil), v...)
+	}
+	for _, name := range r.headers {
+		if _, ok := redacted[name]; ok {
+			redacted[name] = []string{Redacted}
+		}
+	}
+	return redacted
+}
+
+// redactMembers redacts the paths within the member, e.g. "params.", of the
+// request or response, or of all of them in the batch
+func (r *Recorder) redactMembers(body []byte, member string, paths []string) json.RawMessage {
+	if len(paths) == 0 {
+		return body
+	}
+
+	prefix := member
+	if isBatch(body) {
+		prefix = "*." + member
+	}
+
+	prefixed := make([]string, len(paths))
+	for i, p := range paths {
+		prefixed[i] = prefix + p
+	}
+	return Redact(body, prefixed...)
+}
+
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *recordingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// RotatingFile is an io.WriteCloser which rotates the file once it reaches
+// the maximum size. The rotated files get suffix .1, .2 and so on, up to the
+// number of backups; older are removed.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingFile opens the file for appending
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer. The data is never split between files.
+func (f *RotatingFile) Write(b []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(b)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(b)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if f.maxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxBackups))
+		for i := f.maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		}
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return err
+	}
+
+	return f.open()
+}
@@ -0,0 +1,117 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func TestServerRecorder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	login := HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+		return json.RawMessage(`{"user":"a","token":"secret"}`), http.Header{"Set-Cookie": []string{"session=secret"}}, nil
+	})
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{"login": login},
+		jsonrpc.ServerRecorder(jsonrpc.NewRecorder(buf, jsonrpc.RecorderRedactParams("password"), jsonrpc.RecorderRedactResult("token"))),
+	)
+
+	bodies := []string{
+		`{"jsonrpc":"2.0","method":"login","params":{"user":"a","password":"secret"},"id":1}`,
+		`[{"jsonrpc":"2.0","method":"login","params":{"password":"secret"},"id":2}]`,
+		`{"jsonrpc":"2.0","method":"login","params":{"password":"secret"}`,
+	}
+	for _, body := range bodies {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer token")
+		server.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if got, expect := len(lines), len(bodies); got != expect {
+		t.Fatalf("Expected %d recordings, got %d: %s", expect, got, buf.String())
+	}
+
+	recs := make([]jsonrpc.Recording, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &recs[i]); err != nil {
+			t.Fatalf("Recording %d: %s", i, err)
+		}
+		if got, expect := recs[i].Header.Get("Authorization"), jsonrpc.Redacted; got != expect {
+			t.Errorf("Recording %d: expected authorization %q, got %q", i, expect, got)
+		}
+	}
+
+	if got, expect := string(recs[0].Request), `{"id":1,"jsonrpc":"2.0","method":"login","params":{"password":"[REDACTED]","user":"a"}}`; got != expect {
+		t.Errorf("Expected request %s, got %s", expect, got)
+	}
+	if got, expect := string(recs[0].Response), `{"id":1,"jsonrpc":"2.0","result":{"token":"[REDACTED]","user":"a"}}`; got != expect {
+		t.Errorf("Expected response %s, got %s", expect, got)
+	}
+	if got, expect := recs[0].ResponseHeader.Get("Set-Cookie"), jsonrpc.Redacted; got != expect {
+		t.Errorf("Expected response cookie %q, got %q", expect, got)
+	}
+	if got, expect := recs[0].StatusCode, http.StatusOK; got != expect {
+		t.Errorf("Expected status %d, got %d", expect, got)
+	}
+
+	if got, expect := string(recs[1].Request), `[{"id":2,"jsonrpc":"2.0","method":"login","params":{"password":"[REDACTED]"}}]`; got != expect {
+		t.Errorf("Expected batch request %s, got %s", expect, got)
+	}
+	if got, expect := string(recs[1].Response), `[{"id":2,"jsonrpc":"2.0","result":{"token":"[REDACTED]","user":"a"}}]`; got != expect {
+		t.Errorf("Expected batch response %s, got %s", expect, got)
+	}
+
+	if got, expect := recs[2].InvalidRequest, jsonrpc.Unparseable; got != expect {
+		t.Errorf("Expected invalid request %q, got %q", expect, got)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rec.jsonl")
+	f, err := jsonrpc.NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range expect {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("Expected %s to contain %q, got %q", name, content, b)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected no third backup, got %v", err)
+	}
+}
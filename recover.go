@@ -0,0 +1,195 @@
+package jsonrpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+// HeaderCorrelationID carries the correlation id of the error with a cause,
+// see InternalErrorData
+const HeaderCorrelationID = "X-Correlation-Id"
+
+// InternalErrorData is set as the Data of InternalError responses which the
+// Server produces for failures it does not want to expose to the client, e.g.
+// recovered panics, and of the errors with a cause and without Data, see
+// Error.WithCause. The CorrelationID is logged together with the details, so
+// the response can be matched with the log line. The correlation id of the
+// errors with a cause is also sent in the HeaderCorrelationID, as the Data
+// which the Error already has is kept.
+//
+// The details are included only in debug mode, Causes are the messages of
+// the cause chain.
+type InternalErrorData struct {
+	CorrelationID string   `json:"correlationId"`
+	Causes        []string `json:"causes,omitempty"`
+	Stack         string   `json:"stack,omitempty"`
+}
+
+// ServerErrorLogger is used to log errors the Server handles on its own, like
+// recovered panics. By default, no errors are logged.
+func ServerErrorLogger(logger log.Logger) ServerOption {
+	return func(s *Server) { s.logger = logger }
+}
+
+// ServerDebug enables debug mode. In debug mode the Server includes details,
+// like stack traces, in the Data of InternalError responses and of the errors
+// with a cause and without Data. Otherwise the details are only logged. The
+// Data the errors already have is never replaced, their details are only
+// logged even in debug mode.
+func ServerDebug(debug bool) ServerOption {
+	return func(s *Server) { s.debug = debug }
+}
+
+// recoverMiddleware converts panics raised by the next Handlerer into
+// InternalError.
+func (s *Server) recoverMiddleware(next Handlerer) Handlerer {
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (response json.RawMessage, responseHeader http.Header, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			e := NewError(InternalError)
+			e.Data = s.logPanic(ctx, r)
+			response, responseHeader, err = nil, nil, e
+		}()
+
+		return next.ServeJSONRPC(ctx, requestHeader, params)
+	})
+}
+
+// logPanic logs the recovered panic with the stack trace and returns the
+// InternalErrorData of the response
+func (s *Server) logPanic(ctx context.Context, r interface{}) InternalErrorData {
+	method, _ := MethodFromContext(ctx)
+	stack := string(debug.Stack())
+	data := InternalErrorData{CorrelationID: newCorrelationID()}
+	if s.debug {
+		data.Stack = stack
+	}
+
+	s.logger.Log(
+		"method", method,
+		"correlation_id", data.CorrelationID,
+		"err", fmt.Sprintf("panic: %v", r),
+		"stack", stack,
+	)
+	return data
+}
+
+// errorCauseMiddleware logs the cause of the Error returned by the next
+// Handlerer with a correlation id, sent in the HeaderCorrelationID. The Data
+// of the Error is kept, if it has none, the InternalErrorData is set, with
+// the cause chain and the stack trace in debug mode.
+func (s *Server) errorCauseMiddleware(next Handlerer) Handlerer {
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		response, responseHeader, err := next.ServeJSONRPC(ctx, requestHeader, params)
+
+		var e Error
+		if err == nil || !errors.As(err, &e) || e.cause == nil {
+			return response, responseHeader, err
+		}
+
+		method, _ := MethodFromContext(ctx)
+		causes := causeChain(e.cause.err)
+		stack := formatStack(e.cause.stack)
+		data := InternalErrorData{CorrelationID: newCorrelationID()}
+
+		s.logger.Log(
+			"method", method,
+			"correlation_id", data.CorrelationID,
+			"err", e.Message,
+			"cause", strings.Join(causes, ": "),
+			"stack", stack,
+		)
+
+		if e.Data == nil {
+			if s.debug {
+				data.Causes = causes
+				data.Stack = stack
+			}
+			e.Data = data
+		}
+		return response, responseHeader, correlatedError{error: err, e: e, id: data.CorrelationID}
+	})
+}
+
+// correlatedError decorates the error with the Error carrying the
+// InternalErrorData and with the HeaderCorrelationID, the other decorations
+// of the error, e.g. Headerer, are kept
+type correlatedError struct {
+	error
+	e  Error
+	id string
+}
+
+// ErrorCode implements ErrorCoder
+func (e correlatedError) ErrorCode() int { return e.e.Code }
+
+// Headers implements Headerer
+func (e correlatedError) Headers() http.Header {
+	header := http.Header{}
+	if headerer, ok := e.error.(Headerer); ok {
+		for k, values := range headerer.Headers() {
+			header[k] = append([]string(nil), values...)
+		}
+	}
+	header.Set(HeaderCorrelationID, e.id)
+	return header
+}
+
+// As sets the Error with the InternalErrorData as the target
+func (e correlatedError) As(target interface{}) bool {
+	t, ok := target.(*Error)
+	if ok {
+		*t = e.e
+	}
+	return ok
+}
+
+// Unwrap returns the decorated error
+func (e correlatedError) Unwrap() error { return e.error }
+
+// causeChain returns the messages of the error and the errors it wraps
+func causeChain(err error) []string {
+	var causes []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		causes = append(causes, err.Error())
+	}
+	return causes
+}
+
+// formatStack formats the stack trace like runtime/debug.Stack
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s()\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// newCorrelationID returns a random identifier used to correlate responses
+// with log lines.
+func newCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
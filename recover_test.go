@@ -0,0 +1,314 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+	"github.com/go-kit/kit/log"
+)
+
+func panicHandler(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+	panic("dang")
+}
+
+func decodeErrorResponse(t *testing.T, body []byte) (jsonrpc.Error, jsonrpc.InternalErrorData) {
+	var resp struct {
+		Error struct {
+			jsonrpc.Error
+			Data jsonrpc.InternalErrorData `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("Unexpected error unmarshaling response %s: %s", body, err)
+	}
+	return resp.Error.Error, resp.Error.Data
+}
+
+func TestServerRecoversPanic(t *testing.T) {
+	buf := new(bytes.Buffer)
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(panicHandler)},
+		jsonrpc.ServerErrorLogger(log.NewLogfmtLogger(buf)),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	e, data := decodeErrorResponse(t, rw.Body.Bytes())
+	if got, expect := e.Code, jsonrpc.InternalError; got != expect {
+		t.Errorf("Expected error code %d, got %d", expect, got)
+	}
+	if data.CorrelationID == "" {
+		t.Error("Expected correlation id in error data")
+	}
+	if data.Stack != "" {
+		t.Error("Expected no stack trace outside debug mode")
+	}
+
+	logged := buf.String()
+	if !strings.Contains(logged, "correlation_id="+data.CorrelationID) {
+		t.Errorf("Expected correlation id in log, got %s", logged)
+	}
+	if !strings.Contains(logged, `err="panic: dang"`) {
+		t.Errorf("Expected panic in log, got %s", logged)
+	}
+}
+
+func TestServerRecoversPanicDebug(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(panicHandler)},
+		jsonrpc.ServerDebug(true),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	_, data := decodeErrorResponse(t, rw.Body.Bytes())
+	if !strings.Contains(data.Stack, "panicHandler") {
+		t.Errorf("Expected stack trace in error data, got %s", data.Stack)
+	}
+}
+
+type syncWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	done chan struct{}
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer close(w.done)
+	return w.buf.Write(p)
+}
+
+func TestServerRecoversNotificationPanic(t *testing.T) {
+	w := &syncWriter{done: make(chan struct{})}
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(panicHandler)},
+		jsonrpc.ServerErrorLogger(log.NewLogfmtLogger(w)),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s"}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if got, expect := rw.Code, http.StatusNoContent; got != expect {
+		t.Errorf("Expected status code %d, got %d", expect, got)
+	}
+
+	select {
+	case <-w.done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for panic to be logged")
+	}
+}
+
+func errorCauseHandler(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+	e := jsonrpc.NewError(jsonrpc.InternalError)
+	e.Data = "retry later"
+	return nil, nil, e.WithCause(fmt.Errorf("query users: %w", io.ErrUnexpectedEOF))
+}
+
+func TestServerLogsErrorCause(t *testing.T) {
+	buf := new(bytes.Buffer)
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(errorCauseHandler),
+			"wrap": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				return nil, nil, jsonrpc.WrapError(jsonrpc.InternalError, io.EOF)
+			}),
+		},
+		jsonrpc.ServerErrorLogger(log.NewLogfmtLogger(buf)),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if got, expect := rw.Body.String(), `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal JSON-RPC error","data":"retry later"},"id":1}`; strings.TrimSpace(got) != expect {
+		t.Errorf("Expected response %s, got %s", expect, got)
+	}
+	logged := buf.String()
+	if id := rw.Header().Get(jsonrpc.HeaderCorrelationID); id == "" || !strings.Contains(logged, "correlation_id="+id) {
+		t.Errorf("Expected correlation id %q of the header in log, got %s", id, logged)
+	}
+	if !strings.Contains(logged, `cause="query users: unexpected EOF: unexpected EOF"`) {
+		t.Errorf("Expected cause in log, got %s", logged)
+	}
+	if !strings.Contains(logged, "errorCauseHandler") {
+		t.Errorf("Expected stack trace in log, got %s", logged)
+	}
+
+	buf.Reset()
+	r, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"wrap","id":1}`))
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	_, data := decodeErrorResponse(t, rw.Body.Bytes())
+	if data.CorrelationID == "" || data.Causes != nil || data.Stack != "" {
+		t.Errorf("Expected only correlation id in error data, got %+v", data)
+	}
+	if !strings.Contains(buf.String(), "correlation_id="+data.CorrelationID) {
+		t.Errorf("Expected correlation id in log, got %s", buf.String())
+	}
+}
+
+func wrapErrorHandler(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+	return nil, nil, jsonrpc.WrapError(jsonrpc.InternalError, fmt.Errorf("query users: %w", io.ErrUnexpectedEOF))
+}
+
+func TestServerErrorCauseDebug(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(errorCauseHandler),
+			"wrap":         HandlererFunc(wrapErrorHandler),
+		},
+		jsonrpc.ServerDebug(true),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"wrap","id":1}`))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	e, data := decodeErrorResponse(t, rw.Body.Bytes())
+	if got, expect := e.Code, jsonrpc.InternalError; got != expect {
+		t.Errorf("Expected error code %d, got %d", expect, got)
+	}
+	if data.CorrelationID == "" {
+		t.Error("Expected correlation id in error data")
+	}
+	if expect := []string{"query users: unexpected EOF", "unexpected EOF"}; !reflect.DeepEqual(data.Causes, expect) {
+		t.Errorf("Expected causes %q, got %q", expect, data.Causes)
+	}
+	if !strings.Contains(data.Stack, "wrapErrorHandler") {
+		t.Errorf("Expected stack trace in error data, got %s", data.Stack)
+	}
+
+	r, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw = httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if got, expect := rw.Body.String(), `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal JSON-RPC error","data":"retry later"},"id":1}`; strings.TrimSpace(got) != expect {
+		t.Errorf("Expected response %s, got %s", expect, got)
+	}
+}
+
+type retryAfterError struct {
+	error
+}
+
+func (e retryAfterError) Headers() http.Header {
+	return http.Header{"Retry-After": []string{"5"}}
+}
+
+func (e retryAfterError) Unwrap() error { return e.error }
+
+func TestServerErrorCauseKeepsErrors(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		server := jsonrpc.NewServer(
+			jsonrpc.Handlers{
+				"invalid": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+					var b jsonrpc.InvalidParamsBuilder
+					b.Add(jsonrpc.JSONPointer("name"), "required", "is required", nil)
+					var e jsonrpc.Error
+					errors.As(b.Err(), &e)
+					return nil, nil, e.WithCause(io.ErrUnexpectedEOF)
+				}),
+				"busy": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+					return nil, nil, retryAfterError{jsonrpc.WrapError(jsonrpc.ServerBusyError, io.ErrUnexpectedEOF)}
+				}),
+			},
+			jsonrpc.ServerDebug(debug),
+		)
+
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"invalid","id":1}`))
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+
+		var resp struct {
+			Error jsonrpc.Error `json:"error"`
+		}
+		if err := json.Unmarshal(rw.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("debug %t: unexpected error %v", debug, err)
+		}
+		if violations, ok := jsonrpc.InvalidParamsViolations(resp.Error); !ok || len(violations) != 1 || violations[0].Path != "/name" {
+			t.Errorf("debug %t: expected violation of /name, got %s", debug, rw.Body)
+		}
+		if rw.Header().Get(jsonrpc.HeaderCorrelationID) == "" {
+			t.Errorf("debug %t: expected correlation id header", debug)
+		}
+
+		r, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"busy","id":1}`))
+		rw = httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+
+		if got, expect := rw.Header().Get("Retry-After"), "5"; got != expect {
+			t.Errorf("debug %t: expected Retry-After %q, got %q", debug, expect, got)
+		}
+		e, data := decodeErrorResponse(t, rw.Body.Bytes())
+		if got, expect := e.Code, jsonrpc.ServerBusyError; got != expect {
+			t.Errorf("debug %t: expected error code %d, got %d", debug, expect, got)
+		}
+		if data.CorrelationID == "" || rw.Header().Get(jsonrpc.HeaderCorrelationID) != data.CorrelationID {
+			t.Errorf("debug %t: expected correlation id %q in error data and header, got %q", debug, rw.Header().Get(jsonrpc.HeaderCorrelationID), data.CorrelationID)
+		}
+	}
+}
+
+func TestServerRecoversAuthenticatorPanic(t *testing.T) {
+	w := &syncWriter{done: make(chan struct{})}
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)},
+		jsonrpc.ServerAuthenticator(jsonrpc.AuthenticatorFunc(func(context.Context, http.Header) (*jsonrpc.Principal, error) {
+			panic("dang")
+		})),
+		jsonrpc.ServerErrorLogger(log.NewLogfmtLogger(w)),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	e, data := decodeErrorResponse(t, rw.Body.Bytes())
+	if got, expect := e.Code, jsonrpc.InternalError; got != expect {
+		t.Errorf("Expected error code %d, got %d", expect, got)
+	}
+	if !strings.Contains(w.buf.String(), "correlation_id="+data.CorrelationID) {
+		t.Errorf("Expected correlation id in log, got %s", w.buf.String())
+	}
+}
+
+func TestServerRecoversNotificationAuthenticatorPanic(t *testing.T) {
+	w := &syncWriter{done: make(chan struct{})}
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)},
+		jsonrpc.ServerAuthenticator(jsonrpc.AuthenticatorFunc(func(context.Context, http.Header) (*jsonrpc.Principal, error) {
+			panic("dang")
+		})),
+		jsonrpc.ServerErrorLogger(log.NewLogfmtLogger(w)),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s"}`, testMethodName)))
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	select {
+	case <-w.done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for panic to be logged")
+	}
+}
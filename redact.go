@@ -0,0 +1,75 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Redacted replaces the values removed by Redact
+	Redacted = "[REDACTED]"
+
+	// Unparseable replaces the documents Redact cannot parse
+	Unparseable = "[unparseable]"
+)
+
+// unparseable is the JSON string returned by Redact for invalid documents
+var unparseable = json.RawMessage(strconv.Quote(Unparseable))
+
+// Redact replaces the values at the paths in the JSON document with Redacted.
+// A path is a dot separated list of object keys and array indexes, "*"
+// matches any key or index, e.g. "password", "user.token", "cards.*.number".
+// The document is replaced by the Unparseable string if it is not valid JSON,
+// so the values are not leaked.
+func Redact(raw json.RawMessage, paths ...string) json.RawMessage {
+	if len(paths) == 0 || len(raw) == 0 {
+		return raw
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return unparseable
+	}
+
+	for _, path := range paths {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if path == "" {
+			continue
+		}
+		doc = redactPath(doc, strings.Split(path, "."))
+	}
+
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return unparseable
+	}
+	return redacted
+}
+
+// redactPath replaces the values matching the path segments
+func redactPath(v interface{}, segments []string) interface{} {
+	if len(segments) == 0 {
+		return Redacted
+	}
+
+	segment, rest := segments[0], segments[1:]
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if segment == "*" || segment == key {
+				node[key] = redactPath(child, rest)
+			}
+		}
+	case []interface{}:
+		for i, child := range node {
+			if segment == "*" || segment == strconv.Itoa(i) {
+				node[i] = redactPath(child, rest)
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,31 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		raw    string
+		paths  []string
+		expect string
+	}{
+		{`{"user":"a","password":"b"}`, []string{"password"}, `{"password":"[REDACTED]","user":"a"}`},
+		{`{"user":{"name":"a","token":"b"}}`, []string{"$.user.token"}, `{"user":{"name":"a","token":"[REDACTED]"}}`},
+		{`{"cards":[{"number":"1"},{"number":"2"}]}`, []string{"cards.*.number"}, `{"cards":[{"number":"[REDACTED]"},{"number":"[REDACTED]"}]}`},
+		{`["a","b",{"secret":1}]`, []string{"1", "2.secret"}, `["a","[REDACTED]",{"secret":"[REDACTED]"}]`},
+		{`{"amount":12345678901234567890}`, []string{"missing.path"}, `{"amount":12345678901234567890}`},
+		{`not json`, []string{"a"}, `"[unparseable]"`},
+		{`{"a":"secret"`, []string{"a"}, `"[unparseable]"`},
+		{`{"a":1}`, nil, `{"a":1}`},
+	}
+
+	for _, c := range cases {
+		if got := string(jsonrpc.Redact(json.RawMessage(c.raw), c.paths...)); got != c.expect {
+			t.Errorf("TC(%s) Expected %s, got %s", c.raw, c.expect, got)
+		}
+	}
+}
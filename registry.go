@@ -0,0 +1,134 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrorDefinition documents the error code
+type ErrorDefinition struct {
+	Code int
+
+	// Name identifies the error in the documentation, e.g. "NotFound"
+	Name string
+
+	// Message is the default message of the errors with the code, see
+	// NewError
+	Message string
+
+	// Description explains when the error is returned
+	Description string
+}
+
+// Bounds of the error codes reserved by the JSON-RPC specification
+const (
+	// ReservedErrorMin and ReservedErrorMax bound the codes reserved for
+	// the pre-defined errors
+	ReservedErrorMin int = -32768
+	ReservedErrorMax int = -32000
+
+	// ServerErrorMin and ServerErrorMax bound the reserved codes of the
+	// implementation-defined server errors, e.g. TimeoutError
+	ServerErrorMin int = -32099
+	ServerErrorMax int = -32000
+)
+
+// IsReservedErrorCode reports whether the code is reserved by the JSON-RPC
+// specification, these codes cannot be registered by the applications
+func IsReservedErrorCode(code int) bool {
+	return code >= ReservedErrorMin && code <= ReservedErrorMax
+}
+
+// IsServerErrorCode reports whether the code is in the range of the
+// implementation-defined server errors
+func IsServerErrorCode(code int) bool {
+	return code >= ServerErrorMin && code <= ServerErrorMax
+}
+
+var errorRegistry = struct {
+	sync.RWMutex
+	definitions map[int]ErrorDefinition
+}{
+	definitions: predefinedErrors(),
+}
+
+// predefinedErrors returns the definitions of the error codes used by the
+// package
+func predefinedErrors() map[int]ErrorDefinition {
+	definitions := map[int]ErrorDefinition{}
+	for _, d := range []ErrorDefinition{
+		{ParseError, "ParseError", "An error occurred on the server while parsing the JSON text", "Invalid JSON was received by the server."},
+		{InvalidRequestError, "InvalidRequestError", "The JSON sent is not a valid Request object", "The JSON sent is not a valid Request object."},
+		{MethodNotFoundError, "MethodNotFoundError", "The method does not exist / is not available", "The method does not exist or is not available."},
+		{InvalidParamsError, "InvalidParamsError", "Invalid method parameter(s)", "Invalid method parameters, the Data lists the violations if the params were validated."},
+		{InternalError, "InternalError", "Internal JSON-RPC error", "Internal error of the server."},
+		{TimeoutError, "TimeoutError", "Request timed out", "The server did not complete the call in time."},
+		{ServerBusyError, "ServerBusyError", "Server is busy", "The server is overloaded, the Data tells when the call may be retried."},
+		{RateLimitedError, "RateLimitedError", "Too many requests", "The client exceeded the allowed rate of calls, the Data tells when the call may be retried."},
+		{UnauthorizedError, "UnauthorizedError", "Unauthorized", "The client is not authenticated."},
+		{ForbiddenError, "ForbiddenError", "Forbidden", "The client is not allowed to call the method."},
+		{CancelledError, "CancelledError", "Request cancelled", "The call was cancelled before it completed."},
+	} {
+		definitions[d.Code] = d
+	}
+	return definitions
+}
+
+// RegisterError registers the error code of the application, so NewError
+// returns the errors with the default message and the code is listed in the
+// generated OpenRPC documents. It fails if the code is reserved, see
+// IsReservedErrorCode, or if it is already registered.
+func RegisterError(d ErrorDefinition) error {
+	if IsReservedErrorCode(d.Code) {
+		return fmt.Errorf("jsonrpc: error code %d is reserved", d.Code)
+	}
+	if d.Message == "" {
+		return fmt.Errorf("jsonrpc: error code %d has no message", d.Code)
+	}
+
+	errorRegistry.Lock()
+	defer errorRegistry.Unlock()
+	if _, ok := errorRegistry.definitions[d.Code]; ok {
+		return fmt.Errorf("jsonrpc: error code %d is already registered", d.Code)
+	}
+	errorRegistry.definitions[d.Code] = d
+	return nil
+}
+
+// MustRegisterError is like RegisterError, but it panics if the code cannot
+// be registered. It returns the code, e.g.
+//
+//	var ErrorCodeNotFound = jsonrpc.MustRegisterError(jsonrpc.ErrorDefinition{
+//		Code:    404,
+//		Name:    "NotFound",
+//		Message: "Not found",
+//	})
+func MustRegisterError(d ErrorDefinition) int {
+	if err := RegisterError(d); err != nil {
+		panic(err)
+	}
+	return d.Code
+}
+
+// LookupError returns the definition of the code, false if the code is not
+// registered
+func LookupError(code int) (ErrorDefinition, bool) {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	d, ok := errorRegistry.definitions[code]
+	return d, ok
+}
+
+// RegisteredErrors returns the definitions of the pre-defined and registered
+// error codes sorted by the code
+func RegisteredErrors() []ErrorDefinition {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	definitions := make([]ErrorDefinition, 0, len(errorRegistry.definitions))
+	for _, d := range errorRegistry.definitions {
+		definitions = append(definitions, d)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Code < definitions[j].Code })
+	return definitions
+}
@@ -0,0 +1,72 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+var errorCodeNotFound = jsonrpc.MustRegisterError(jsonrpc.ErrorDefinition{
+	Code:        4040,
+	Name:        "NotFound",
+	Message:     "Not found",
+	Description: "The resource does not exist.",
+})
+
+func TestRegisterError(t *testing.T) {
+	code := errorCodeNotFound
+
+	if got, expect := jsonrpc.NewError(code).Message, "Not found"; got != expect {
+		t.Errorf("Expected message %q, got %q", expect, got)
+	}
+	if d, ok := jsonrpc.LookupError(code); !ok || d.Description != "The resource does not exist." {
+		t.Errorf("Expected the definition of %d, got %v", code, d)
+	}
+
+	doc := jsonrpc.NewOpenRPCDocument(jsonrpc.OpenRPCInfo{Title: "API", Version: "1"}, jsonrpc.Handlers{})
+	if got, expect := doc.Components.Errors["NotFound"], (jsonrpc.OpenRPCError{Code: 4040, Message: "Not found", Description: "The resource does not exist."}); got != expect {
+		t.Errorf("Expected %v in the components, got %v", expect, got)
+	}
+
+	errors := jsonrpc.RegisteredErrors()
+	if errors[0].Code != jsonrpc.ParseError || errors[len(errors)-1].Code < code {
+		t.Errorf("Expected errors sorted by code, got %v", errors)
+	}
+
+	for _, d := range []jsonrpc.ErrorDefinition{
+		{Code: 4040, Message: "Duplicate"},
+		{Code: -32000, Message: "Reserved"},
+		{Code: -32768, Message: "Reserved"},
+		{Code: -32050, Message: "Server error"},
+		{Code: 4041},
+	} {
+		if err := jsonrpc.RegisterError(d); err == nil {
+			t.Errorf("%d: expected error", d.Code)
+		}
+	}
+	assertPanic(t, "MustRegisterError", func() {
+		jsonrpc.MustRegisterError(jsonrpc.ErrorDefinition{Code: jsonrpc.TimeoutError, Message: "Timeout"})
+	})
+}
+
+func TestErrorCodeRanges(t *testing.T) {
+	tests := []struct {
+		code             int
+		reserved, server bool
+	}{
+		{jsonrpc.ParseError, true, false},
+		{-32769, false, false},
+		{jsonrpc.ServerErrorMin, true, true},
+		{jsonrpc.CancelledError, true, true},
+		{-31999, false, false},
+		{0, false, false},
+	}
+	for _, tt := range tests {
+		if got := jsonrpc.IsReservedErrorCode(tt.code); got != tt.reserved {
+			t.Errorf("%d: expected reserved %v, got %v", tt.code, tt.reserved, got)
+		}
+		if got := jsonrpc.IsServerErrorCode(tt.code); got != tt.server {
+			t.Errorf("%d: expected server error %v, got %v", tt.code, tt.server, got)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -28,8 +29,14 @@ type Request struct {
 	ID      *RequestID      `json:"id"`
 }
 
-// Validate request
+// Validate request. Methods reserved by the spec, see IsReservedMethod, are
+// invalid, Server accepts the ones registered by ServerReservedMethod.
 func (r *Request) Validate() error {
+	return r.validate(false)
+}
+
+// validate the request, reserved methods are valid if allowed
+func (r *Request) validate(allowReserved bool) error {
 	// A String specifying the version of the JSON-RPC protocol. MUST be exactly "2.0"
 	if r.JSONRPC != Version {
 		return NewError(InvalidRequestError)
@@ -44,7 +51,7 @@ func (r *Request) Validate() error {
 	// A String containing the name of the method to be invoked.
 	// Method names that begin with the word rpc followed by a period character (U+002E or ASCII 46) are reserved for
 	// rpc-internal methods and extensions and MUST NOT be used for anything else.
-	if r.Method == "" || strings.HasPrefix(r.Method, "rpc.") {
+	if r.Method == "" || (!allowReserved && IsReservedMethod(r.Method)) {
 		return NewError(InvalidRequestError)
 	}
 
@@ -120,6 +127,16 @@ func (id *RequestID) String() (string, error) {
 	return id.stringValue, id.stringError
 }
 
+// formatRequestID returns the ID as a string without quotes, the empty string
+// for nil ID
+func formatRequestID(id *RequestID) string {
+	if id == nil {
+		return ""
+	}
+	b, _ := id.MarshalJSON()
+	return strings.Trim(string(b), `"`)
+}
+
 // Response defines a JSON RPC response from the spec
 // http://www.jsonrpc.org/specification#response_object
 type Response struct {
@@ -135,6 +152,40 @@ func (r Response) Headers() http.Header {
 	return r.RespHeaders
 }
 
+// BatchResponse defines a JSON RPC batch response from the spec
+// http://www.jsonrpc.org/specification#batch
+type BatchResponse []Response
+
+// Headers returns response headers of all responses. The Retry-After header
+// is set once, to the longest delay of the responses.
+func (r BatchResponse) Headers() http.Header {
+	header := http.Header{}
+	for _, res := range r {
+		for k, values := range res.RespHeaders {
+			if k == "Retry-After" && len(values) > 0 {
+				header.Set(k, maxRetryAfter(append(header[k], values...)))
+				continue
+			}
+			for _, v := range values {
+				header.Add(k, v)
+			}
+		}
+	}
+	return header
+}
+
+// maxRetryAfter returns the longest delay in seconds, the first value if none
+// is a number
+func maxRetryAfter(values []string) string {
+	longest, max := values[0], -1
+	for _, v := range values {
+		if sec, err := strconv.Atoi(v); err == nil && sec > max {
+			longest, max = v, sec
+		}
+	}
+	return longest
+}
+
 // NotificationResponse defines a JSON RPC notification response
 type NotificationResponse struct{}
 
@@ -151,6 +202,7 @@ func PopulateRequestContext(ctx context.Context, r *Request) context.Context {
 		ContextKeyRequestJSONRPC: r.JSONRPC,
 		ContextKeyRequestMethod:  r.Method,
 		ContextKeyRequestID:      r.ID,
+		ContextKeyRequest:        r,
 	} {
 		ctx = context.WithValue(ctx, k, v)
 	}
@@ -168,4 +220,30 @@ const (
 
 	// ContextKeyRequestID is populated in the context by PopulateRequestContext
 	ContextKeyRequestID
+
+	// ContextKeyRequestBody is populated in the context by Server with the raw
+	// bytes of the HTTP request body
+	ContextKeyRequestBody
+
+	// ContextKeyRequest is populated in the context by PopulateRequestContext
+	// with the decoded Request
+	ContextKeyRequest
+
+	// ContextKeyRequestMessage is populated in the context by Server with the
+	// raw bytes of the Request object
+	ContextKeyRequestMessage
+
+	// ContextKeyRequestInfo is populated in the context by Server with the
+	// RequestInfo
+	ContextKeyRequestInfo
+
+	// ContextKeyRequestAcceptLanguage is populated in the context by Server
+	// with the Accept-Language header of the HTTP request
+	ContextKeyRequestAcceptLanguage
+
+	contextKeyRequestHeader
+	contextKeyPrincipal
+	contextKeyAuthentication
+	contextKeySpanContext
+	contextKeyTranslator
 )
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
@@ -302,3 +304,41 @@ func equalRequestID(r1, r2 *jsonrpc.RequestID) bool {
 	b2, _ := r2.MarshalJSON()
 	return bytes.Equal(b1, b2)
 }
+
+func TestServerPopulatesRawRequest(t *testing.T) {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","params":[1],"id":1,"trace":{"span":"a"}}`, testMethodName)
+
+	h := HandlererFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		raw, ok := jsonrpc.RawRequestFromContext(ctx)
+		if !ok || string(raw) != body {
+			t.Errorf("Expecting raw request %s, got %s", body, raw)
+		}
+
+		msg, ok := jsonrpc.RequestMessageFromContext(ctx)
+		if !ok || string(msg) != body {
+			t.Errorf("Expecting request message %s, got %s", body, msg)
+		}
+
+		req, ok := jsonrpc.RequestFromContext(ctx)
+		if !ok {
+			t.Fatal("Expecting request in the context")
+		}
+		if got, want := req.Method, testMethodName; got != want {
+			t.Errorf("Expecting method %s, got %s", want, got)
+		}
+
+		ext := jsonrpc.RequestExtensions(ctx)
+		if got, want := len(ext), 1; got != want {
+			t.Fatalf("Expecting %d extension, got %d", want, got)
+		}
+		if got, want := string(ext["trace"]), `{"span":"a"}`; got != want {
+			t.Errorf("Expecting extension %s, got %s", want, got)
+		}
+		return nil, nil, nil
+	})
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if _, err := testServer(r, h); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+}
@@ -0,0 +1,99 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// IsReservedMethod reports whether the method name begins with "rpc.", such
+// methods are reserved for rpc-internal methods and extensions.
+func IsReservedMethod(method string) bool {
+	return strings.HasPrefix(method, "rpc.")
+}
+
+// ServerReservedMethod registers the handler of the reserved method, e.g. an
+// extension of the protocol. It panics if the method is not reserved.
+func ServerReservedMethod(method string, h Handlerer) ServerOption {
+	if !IsReservedMethod(method) {
+		panic(fmt.Sprintf("Method %s is not reserved", method))
+	}
+	return func(s *Server) {
+		if s.reserved == nil {
+			s.reserved = Handlers{}
+		}
+		s.reserved[method] = h
+	}
+}
+
+// ServerIntrospection registers the reserved methods describing the service:
+//
+//	rpc.discover  returns the OpenRPC document of the handlers
+//	rpc.methods   returns the names of the methods
+//	rpc.ping      returns "pong"
+//
+// The reserved methods are served by the same middlewares as the handlers, use
+// ServerMethodPolicy to make them public if ServerAuthenticator is set.
+func ServerIntrospection(info OpenRPCInfo, servers ...OpenRPCServer) ServerOption {
+	return func(s *Server) {
+		s.introspection = &OpenRPCDocument{Info: info, Servers: servers}
+	}
+}
+
+// reservedHandlers returns a copy of the handlers with the reserved methods
+// registered by the options. Reserved methods of the handlers are dropped.
+func (s *Server) reservedHandlers(sh Handlers) Handlers {
+	handlers := make(Handlers, len(sh)+len(s.reserved))
+	for method, h := range sh {
+		if !IsReservedMethod(method) {
+			handlers[method] = h
+		}
+	}
+
+	if s.introspection != nil {
+		doc := NewOpenRPCDocument(s.introspection.Info, handlers, s.introspection.Servers...)
+		for method, h := range introspectionHandlers(doc) {
+			handlers[method] = h
+		}
+	}
+	for method, h := range s.reserved {
+		handlers[method] = h
+	}
+	return handlers
+}
+
+// introspectionHandlers returns the handlers of the methods registered by
+// ServerIntrospection
+func introspectionHandlers(doc OpenRPCDocument) Handlers {
+	methods := make([]string, len(doc.Methods))
+	for i, m := range doc.Methods {
+		methods[i] = m.Name
+	}
+	sort.Strings(methods)
+
+	return Handlers{
+		"rpc.discover": Describe(resultHandler(doc), MethodDoc{
+			Summary: "Returns the OpenRPC document of the service",
+			Result:  &Schema{Ref: "https://meta.open-rpc.org/"},
+		}),
+		"rpc.methods": Describe(resultHandler(methods), MethodDoc{
+			Summary: "Returns the names of the methods",
+			Result:  SchemaOf(methods),
+		}),
+		"rpc.ping": Describe(resultHandler("pong"), MethodDoc{
+			Summary: "Checks the service is available",
+			Result:  &Schema{Const: "pong"},
+		}),
+	}
+}
+
+// resultHandler returns a Handlerer which always responds with the result
+func resultHandler(result interface{}) Handlerer {
+	b, err := json.Marshal(result)
+	return HandlerFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+		return b, nil, err
+	})
+}
@@ -0,0 +1,113 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func serveReserved(t *testing.T, server http.Handler, method string) (json.RawMessage, *jsonrpc.Error) {
+	t.Helper()
+
+	body := `{"jsonrpc":"2.0","method":"` + method + `","id":1}`
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *jsonrpc.Error  `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: %s", method, err)
+	}
+	return resp.Result, resp.Error
+}
+
+func TestServerIntrospection(t *testing.T) {
+	handlers := jsonrpc.Handlers{
+		"user.get":  jsonrpc.Describe(HandlererFunc(nopHandler), jsonrpc.MethodDoc{Summary: "Returns the user"}),
+		"rpc.evil":  HandlererFunc(nopHandler),
+		"user.list": HandlererFunc(nopHandler),
+	}
+	server := jsonrpc.NewServer(
+		handlers,
+		jsonrpc.ServerIntrospection(jsonrpc.OpenRPCInfo{Title: "Users", Version: "1.0.0"}),
+		jsonrpc.ServerHandlerMiddleware(jsonrpc.Chain(func(next jsonrpc.Handlerer) jsonrpc.Handlerer { return next })),
+	)
+
+	result, rpcErr := serveReserved(t, server, "rpc.methods")
+	if rpcErr != nil {
+		t.Fatal(rpcErr)
+	}
+	if got, expect := string(result), `["user.get","user.list"]`; got != expect {
+		t.Errorf("Expected methods %s, got %s", expect, got)
+	}
+
+	result, rpcErr = serveReserved(t, server, "rpc.ping")
+	if rpcErr != nil {
+		t.Fatal(rpcErr)
+	}
+	if got, expect := string(result), `"pong"`; got != expect {
+		t.Errorf("Expected %s, got %s", expect, got)
+	}
+
+	result, rpcErr = serveReserved(t, server, "rpc.discover")
+	if rpcErr != nil {
+		t.Fatal(rpcErr)
+	}
+	var doc jsonrpc.OpenRPCDocument
+	if err := json.Unmarshal(result, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := doc.Info.Title, "Users"; got != expect {
+		t.Errorf("Expected title %s, got %s", expect, got)
+	}
+	if len(doc.Methods) != 2 || doc.Methods[0].Summary != "Returns the user" {
+		t.Errorf("Expected described user methods, got %+v", doc.Methods)
+	}
+
+	for _, method := range []string{"rpc.evil", "rpc.unknown"} {
+		if _, rpcErr := serveReserved(t, server, method); rpcErr == nil || rpcErr.Code != jsonrpc.InvalidRequestError {
+			t.Errorf("%s: expected invalid request, got %v", method, rpcErr)
+		}
+	}
+
+	if _, ok := handlers["rpc.evil"]; !ok {
+		t.Error("Expected the handlers not to be modified")
+	}
+}
+
+func TestServerReservedMethod(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{},
+		jsonrpc.ServerReservedMethod("rpc.echo", HandlererFunc(nopHandler)),
+	)
+	if _, rpcErr := serveReserved(t, server, "rpc.echo"); rpcErr != nil {
+		t.Errorf("Expected no error, got %v", rpcErr)
+	}
+	if _, rpcErr := serveReserved(t, server, "rpc.ping"); rpcErr == nil {
+		t.Error("Expected rpc.ping not to be registered without introspection")
+	}
+
+	assertPanic(t, "not reserved method", func() {
+		jsonrpc.ServerReservedMethod("echo", HandlererFunc(nopHandler))
+	})
+	assertPanic(t, "Handlers.Set with reserved method", func() {
+		jsonrpc.Handlers{}.Set("rpc.echo", HandlererFunc(nopHandler))
+	})
+}
+
+func assertPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: expected panic", name)
+		}
+	}()
+	f()
+}
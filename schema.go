@@ -0,0 +1,313 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) used to describe params and
+// results of the methods. Nullable allows null besides the Type, it is
+// encoded as the type array, e.g. "type": ["integer", "null"].
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Nullable    bool               `json:"-"`
+	Format      string             `json:"format,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	// Const is the only allowed value unless nil, use Type "null" for the
+	// constant null
+	Const      interface{} `json:"const,omitempty"`
+	Default    interface{} `json:"default,omitempty"`
+	Deprecated bool        `json:"deprecated,omitempty"`
+
+	// object
+	Properties           map[string]*Schema  `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema  `json:"patternProperties,omitempty"`
+	AdditionalProperties *Schema             `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema             `json:"propertyNames,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	DependentRequired    map[string][]string `json:"dependentRequired,omitempty"`
+	DependentSchemas     map[string]*Schema  `json:"dependentSchemas,omitempty"`
+	MinProperties        *int                `json:"minProperties,omitempty"`
+	MaxProperties        *int                `json:"maxProperties,omitempty"`
+
+	// array
+	Items       *Schema   `json:"items,omitempty"`
+	PrefixItems []*Schema `json:"prefixItems,omitempty"`
+	Contains    *Schema   `json:"contains,omitempty"`
+	MinContains *int      `json:"minContains,omitempty"`
+	MaxContains *int      `json:"maxContains,omitempty"`
+	MinItems    *int      `json:"minItems,omitempty"`
+	MaxItems    *int      `json:"maxItems,omitempty"`
+	UniqueItems bool      `json:"uniqueItems,omitempty"`
+
+	// number
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+
+	// string
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+	If    *Schema   `json:"if,omitempty"`
+	Then  *Schema   `json:"then,omitempty"`
+	Else  *Schema   `json:"else,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	if !s.Nullable || s.Type == "" || s.Type == "null" {
+		return json.Marshal(schema(s))
+	}
+	return json.Marshal(struct {
+		schema
+		Type []string `json:"type"`
+	}{schema(s), []string{s.Type, "null"}})
+}
+
+// unsupportedKeywords change the validation of JSON Schema, but Validate does
+// not implement them
+var unsupportedKeywords = []string{"$dynamicRef", "$recursiveRef", "dependencies", "unevaluatedItems", "unevaluatedProperties"}
+
+// UnmarshalJSON implements json.Unmarshaler. Besides the schema objects it
+// accepts the boolean schemas, true allows any value and false none, e.g.
+// "additionalProperties": false. The type array of a type and "null" sets
+// Nullable, the arrays of more types allow any value. The constant null is
+// added to AllOf as Type "null". The schemas with the keywords changing the
+// validation which Validate does not implement, e.g. unevaluatedProperties,
+// are rejected.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	var allow bool
+	if err := json.Unmarshal(b, &allow); err == nil {
+		*s = Schema{}
+		if !allow {
+			s.Not = &Schema{}
+		}
+		return nil
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keywords); err != nil {
+		return err
+	}
+	for _, keyword := range unsupportedKeywords {
+		if _, ok := keywords[keyword]; ok {
+			return fmt.Errorf("jsonrpc: schema keyword %s is not supported", keyword)
+		}
+	}
+
+	type schema Schema
+	v := struct {
+		*schema
+		Type json.RawMessage `json:"type"`
+	}{schema: (*schema)(s)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if c, ok := keywords["const"]; ok && string(bytes.TrimSpace(c)) == "null" {
+		s.AllOf = append(s.AllOf, &Schema{Type: "null"})
+	}
+	if len(v.Type) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(v.Type, &s.Type); err == nil {
+		return nil
+	}
+	var types []string
+	if err := json.Unmarshal(v.Type, &types); err != nil {
+		return err
+	}
+	s.Type = ""
+	for _, t := range types {
+		switch {
+		case t == "null":
+			s.Nullable = true
+		case s.Type == "":
+			s.Type = t
+		default:
+			s.Type, s.Nullable = "", false
+			return nil
+		}
+	}
+	if s.Type == "" && s.Nullable {
+		s.Type, s.Nullable = "null", false
+	}
+	return nil
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaOf derives the Schema of the JSON encoding of the value's type, as
+// produced by encoding/json. Struct fields are described by their json tags,
+// the description tag sets the description of the property and the fields
+// with the required rule in the validate tag are required, see Validator,
+// e.g.
+//
+//	type AddRequest struct {
+//		A int  `json:"a" description:"first operand" validate:"required"`
+//		B *int `json:"b"`
+//	}
+//
+// As encoding/json decodes missing fields and null into pointers, the other
+// fields are optional and the pointers are Nullable.
+//
+// Types implementing json.Marshaler, except time.Time, and interfaces are
+// described by the empty schema which allows any value. Recursive types are
+// cut at the first repetition with the schema of the kind, e.g. object.
+func SchemaOf(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return schemaOf(t, map[reflect.Type]bool{})
+}
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := schemaOf(t.Elem(), visiting)
+		if s.Type != "" {
+			s.Nullable = true
+		}
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return &Schema{}
+	case t.Kind() != reflect.String && (t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		min := 0.0
+		return &Schema{Type: "integer", Minimum: &min}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), visiting)}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), visiting), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addStructProperties(s, t, visiting)
+		return s
+	}
+
+	return &Schema{}
+}
+
+// addStructProperties adds the exported fields of the struct to the schema,
+// the fields of embedded structs are promoted as by encoding/json
+func addStructProperties(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseJSONTag(tag)
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// the fields of the struct embedding itself are already added
+			if !visiting[ft] {
+				visiting[ft] = true
+				addStructProperties(s, ft, visiting)
+				delete(visiting, ft)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaOf(f.Type, visiting)
+		if opts.contains("string") {
+			switch prop.Type {
+			case "boolean", "integer", "number":
+				prop = &Schema{Type: "string", Nullable: prop.Nullable}
+			}
+		}
+		if description := f.Tag.Get("description"); description != "" {
+			prop.Description = description
+		}
+
+		s.Properties[name] = prop
+		if hasRule(f.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+type tagOptions string
+
+func parseJSONTag(tag string) (string, tagOptions) {
+	if i := strings.Index(tag, ","); i != -1 {
+		return tag[:i], tagOptions(tag[i+1:])
+	}
+	return tag, ""
+}
+
+func (o tagOptions) contains(name string) bool {
+	for _, opt := range strings.Split(string(o), ",") {
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
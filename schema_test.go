@@ -0,0 +1,140 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type schemaBase struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type schemaNode struct {
+	Name     string        `json:"name" validate:"required"`
+	Children []*schemaNode `json:"children,omitempty"`
+}
+
+type schemaSelfEmbedding struct {
+	*schemaSelfEmbedding
+	X int `json:"x"`
+}
+
+type schemaExample struct {
+	schemaBase
+	Name      string          `json:"name" description:"full name" validate:"required"`
+	Age       uint8           `json:"age,omitempty"`
+	Score     float64         `json:"score,string"`
+	Tags      []string        `json:"tags"`
+	Point     [2]int          `json:"point"`
+	Labels    map[string]bool `json:"labels"`
+	Nickname  *string         `json:"nickname"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Raw       json.RawMessage `json:"raw"`
+	Any       interface{}     `json:"any"`
+	Data      []byte          `json:"data"`
+	Tree      schemaNode      `json:"tree"`
+	Skipped   string          `json:"-"`
+	NoTag     int
+	unexposed int
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	b, err := json.Marshal(jsonrpc.SchemaOf(schemaExample{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"type":"object","properties":{` +
+		`"NoTag":{"type":"integer"},` +
+		`"age":{"type":"integer","minimum":0},` +
+		`"any":{},` +
+		`"createdAt":{"type":"string","format":"date-time"},` +
+		`"data":{"type":"string","format":"byte"},` +
+		`"headers":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"id":{"type":"string"},` +
+		`"labels":{"type":"object","additionalProperties":{"type":"boolean"}},` +
+		`"name":{"description":"full name","type":"string"},` +
+		`"nickname":{"type":["string","null"]},` +
+		`"point":{"type":"array","items":{"type":"integer"},"minItems":2,"maxItems":2},` +
+		`"raw":{},` +
+		`"score":{"type":"string"},` +
+		`"tags":{"type":"array","items":{"type":"string"}},` +
+		`"tree":{"type":"object","properties":{"children":{"type":"array","items":{"type":["object","null"]}},"name":{"type":"string"}},"required":["name"]}` +
+		`},"required":["id","name"]}`
+	if got := string(b); got != expect {
+		t.Errorf("Expected\n%s\ngot\n%s", expect, got)
+	}
+}
+
+func TestSchemaOfScalars(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		schema string
+	}{
+		{nil, `{}`},
+		{true, `{"type":"boolean"}`},
+		{1, `{"type":"integer"}`},
+		{1.5, `{"type":"number"}`},
+		{"a", `{"type":"string"}`},
+		{[]int{}, `{"type":"array","items":{"type":"integer"}}`},
+		{new(int), `{"type":"integer"}`},
+		{[]*int{}, `{"type":"array","items":{"type":["integer","null"]}}`},
+	}
+	for _, tt := range tests {
+		b, _ := json.Marshal(jsonrpc.SchemaOf(tt.value))
+		if got := string(b); got != tt.schema {
+			t.Errorf("%T: expected %s, got %s", tt.value, tt.schema, got)
+		}
+	}
+}
+
+func TestSchemaOfSelfEmbedding(t *testing.T) {
+	b, err := json.Marshal(jsonrpc.SchemaOf(schemaSelfEmbedding{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := string(b), `{"type":"object","properties":{"x":{"type":"integer"}}}`; got != expect {
+		t.Errorf("Expected %s, got %s", expect, got)
+	}
+}
+
+func TestSchemaUnmarshalType(t *testing.T) {
+	tests := []struct {
+		schema   string
+		typ      string
+		nullable bool
+	}{
+		{`{"type":"integer"}`, "integer", false},
+		{`{"type":["integer","null"]}`, "integer", true},
+		{`{"type":["null","string"]}`, "string", true},
+		{`{"type":["null"]}`, "null", false},
+		{`{"type":["integer","string"]}`, "", false},
+		{`{}`, "", false},
+	}
+	for _, tt := range tests {
+		var s jsonrpc.Schema
+		if err := json.Unmarshal([]byte(tt.schema), &s); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.schema, err)
+			continue
+		}
+		if s.Type != tt.typ || s.Nullable != tt.nullable {
+			t.Errorf("%s: expected type %q nullable %t, got %q %t", tt.schema, tt.typ, tt.nullable, s.Type, s.Nullable)
+		}
+	}
+}
+
+func TestSchemaUnmarshalUnsupportedKeywords(t *testing.T) {
+	for _, schema := range []string{
+		`{"type":"object","unevaluatedProperties":false}`,
+		`{"properties":{"a":{"dependencies":{"b":["c"]}}}}`,
+	} {
+		var s jsonrpc.Schema
+		if err := json.Unmarshal([]byte(schema), &s); err == nil {
+			t.Errorf("%s: expected error", schema)
+		}
+	}
+}
@@ -0,0 +1,501 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ServerValidateParams validates the params against the schema of the method,
+// see MethodDoc.Params, before the Handlerer decodes them. Invalid params are
+// rejected with InvalidParamsError with the InvalidParamsData listing all the
+// violations. Methods without the schema of the params are not validated.
+//
+// The schemas derived by SchemaOf accept the missing fields and null for
+// pointers, like encoding/json. Only the fields with the required rule in the
+// validate tag are required. Unlike encoding/json, null is rejected for the
+// fields other than pointers.
+func ServerValidateParams() ServerOption {
+	return func(s *Server) { s.validateParams = true }
+}
+
+// paramsSchemas returns the schemas of the params of the described handlers
+func paramsSchemas(sh Handlers) map[string]*Schema {
+	schemas := map[string]*Schema{}
+	for method := range sh {
+		if doc, ok := sh.Doc(method); ok && doc.Params != nil {
+			schemas[method] = doc.Params
+		}
+	}
+	return schemas
+}
+
+// validateParamsMiddleware rejects the calls with params not valid against
+// the schema of the method
+func (s *Server) validateParamsMiddleware(next Handlerer) Handlerer {
+	if !s.validateParams {
+		return next
+	}
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		method, _ := MethodFromContext(ctx)
+		if schema, ok := s.paramsSchemas[method]; ok {
+			if violations := schema.validateParams(params); len(violations) > 0 {
+				b := InvalidParamsBuilder{violations: violations}
+				return nil, nil, b.Err()
+			}
+		}
+		return next.ServeJSONRPC(ctx, requestHeader, params)
+	})
+}
+
+// validateParams validates the params, omitted params are validated as the
+// empty object or array, depending on the type of the schema
+func (s *Schema) validateParams(params json.RawMessage) []Violation {
+	if len(params) > 0 {
+		return s.Validate(params)
+	}
+	switch s.Type {
+	case "object":
+		return s.Validate(json.RawMessage("{}"))
+	case "array":
+		return s.Validate(json.RawMessage("[]"))
+	}
+	return nil
+}
+
+// Validate validates the JSON value against the schema and returns all the
+// violations, none if the value is valid. The validation follows JSON Schema
+// draft 2020-12 for the keywords of Schema, Format is an annotation and it is
+// not validated. Pattern uses the syntax of the regexp package. $ref is
+// resolved only within the schema, "#" and "#/$defs/name", other references
+// are ignored.
+func (s *Schema) Validate(value json.RawMessage) []Violation {
+	d := json.NewDecoder(bytes.NewReader(value))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return []Violation{{Code: "parse", Message: "invalid JSON"}}
+	}
+
+	vl := &schemaValidator{root: s}
+	vl.validate(s, v, "")
+	return vl.params.Violations()
+}
+
+// maxRefDepth limits the nesting of references to stop the cycles
+const maxRefDepth = 100
+
+type schemaValidator struct {
+	root   *Schema
+	depth  int
+	params InvalidParamsBuilder
+}
+
+// fail adds the violation of the keyword, the code, by the value
+func (vl *schemaValidator) fail(path, code string, v interface{}, format string, args ...interface{}) {
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		v = nil
+	}
+	vl.params.Add(path, code, fmt.Sprintf(format, args...), v)
+}
+
+// matches reports whether the value is valid against the schema without
+// recording the violations
+func (vl *schemaValidator) matches(s *Schema, v interface{}, path string) bool {
+	sub := &schemaValidator{root: vl.root, depth: vl.depth}
+	sub.validate(s, v, path)
+	return len(sub.params.Violations()) == 0
+}
+
+func (vl *schemaValidator) validate(s *Schema, v interface{}, path string) {
+	if s == nil {
+		return
+	}
+
+	if isFalseSchema(s) {
+		vl.fail(path, "not", v, "is not allowed")
+		return
+	}
+	if s.Not != nil {
+		if vl.matches(s.Not, v, path) {
+			vl.fail(path, "not", v, "must not match the schema")
+		}
+	}
+
+	if s.Ref != "" && vl.depth < maxRefDepth {
+		if ref, ok := vl.resolve(s.Ref); ok {
+			vl.depth++
+			vl.validate(ref, v, path)
+			vl.depth--
+		}
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) && !(s.Nullable && v == nil) {
+		vl.fail(path, "type", v, "expected %s, got %s", s.Type, typeOf(v))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		vl.fail(path, "enum", v, "must be one of %s", encodeValues(s.Enum))
+	}
+	if s.Const != nil && !equalValues(normalizeValue(s.Const), v) {
+		vl.fail(path, "const", v, "must be %s", encodeValues(s.Const))
+	}
+
+	switch v := v.(type) {
+	case string:
+		vl.validateString(s, v, path)
+	case json.Number:
+		vl.validateNumber(s, v, path)
+	case []interface{}:
+		vl.validateArray(s, v, path)
+	case map[string]interface{}:
+		vl.validateObject(s, v, path)
+	}
+
+	for _, sub := range s.AllOf {
+		vl.validate(sub, v, path)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if vl.matches(sub, v, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			vl.fail(path, "anyOf", v, "must match at least one of the schemas")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			if vl.matches(sub, v, path) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			vl.fail(path, "oneOf", v, "must match exactly one of the schemas, matched %d", matched)
+		}
+	}
+	if s.If != nil {
+		if vl.matches(s.If, v, path) {
+			vl.validate(s.Then, v, path)
+		} else {
+			vl.validate(s.Else, v, path)
+		}
+	}
+}
+
+func (vl *schemaValidator) validateString(s *Schema, v string, path string) {
+	n := utf8.RuneCountInString(v)
+	if s.MinLength != nil && n < *s.MinLength {
+		vl.fail(path, "minLength", v, "must be at least %d characters long", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		vl.fail(path, "maxLength", v, "must be at most %d characters long", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			vl.fail(path, "pattern", v, "cannot be matched with invalid pattern %q", s.Pattern)
+		} else if !re.MatchString(v) {
+			vl.fail(path, "pattern", v, "must match pattern %q", s.Pattern)
+		}
+	}
+}
+
+func (vl *schemaValidator) validateNumber(s *Schema, v json.Number, path string) {
+	f, err := strconv.ParseFloat(string(v), 64)
+	if err != nil {
+		return
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		vl.fail(path, "minimum", v, "must be greater than or equal to %s", formatNumber(*s.Minimum))
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		vl.fail(path, "maximum", v, "must be less than or equal to %s", formatNumber(*s.Maximum))
+	}
+	if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+		vl.fail(path, "exclusiveMinimum", v, "must be greater than %s", formatNumber(*s.ExclusiveMinimum))
+	}
+	if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+		vl.fail(path, "exclusiveMaximum", v, "must be less than %s", formatNumber(*s.ExclusiveMaximum))
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		if q := f / *s.MultipleOf; q != math.Trunc(q) {
+			vl.fail(path, "multipleOf", v, "must be a multiple of %s", formatNumber(*s.MultipleOf))
+		}
+	}
+}
+
+func (vl *schemaValidator) validateArray(s *Schema, v []interface{}, path string) {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		vl.fail(path, "minItems", v, "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		vl.fail(path, "maxItems", v, "must have at most %d items", *s.MaxItems)
+	}
+	if s.UniqueItems {
+	unique:
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if equalValues(v[i], v[j]) {
+					vl.fail(path, "uniqueItems", v, "must have unique items, items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	for i, item := range v {
+		itemPath := path + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(s.PrefixItems):
+			vl.validate(s.PrefixItems[i], item, itemPath)
+		case isFalseSchema(s.Items):
+			vl.fail(itemPath, "items", item, "is not allowed")
+		default:
+			vl.validate(s.Items, item, itemPath)
+		}
+	}
+
+	if s.Contains != nil {
+		contains := 0
+		for i, item := range v {
+			if vl.matches(s.Contains, item, path+"/"+strconv.Itoa(i)) {
+				contains++
+			}
+		}
+		min := 1
+		if s.MinContains != nil {
+			min = *s.MinContains
+		}
+		if contains < min {
+			vl.fail(path, "contains", v, "must contain at least %d matching items", min)
+		}
+		if s.MaxContains != nil && contains > *s.MaxContains {
+			vl.fail(path, "maxContains", v, "must contain at most %d matching items", *s.MaxContains)
+		}
+	}
+}
+
+func (vl *schemaValidator) validateObject(s *Schema, v map[string]interface{}, path string) {
+	if s.MinProperties != nil && len(v) < *s.MinProperties {
+		vl.fail(path, "minProperties", v, "must have at least %d properties", *s.MinProperties)
+	}
+	if s.MaxProperties != nil && len(v) > *s.MaxProperties {
+		vl.fail(path, "maxProperties", v, "must have at most %d properties", *s.MaxProperties)
+	}
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			vl.fail(path+"/"+escapePointer(name), "required", nil, "is required")
+		}
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	patterns := make([]string, 0, len(s.PatternProperties))
+	for pattern := range s.PatternProperties {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, name := range names {
+		propPath := path + "/" + escapePointer(name)
+		if s.PropertyNames != nil && !vl.matches(s.PropertyNames, name, propPath) {
+			vl.fail(propPath, "propertyNames", nil, "is not a valid property name")
+		}
+
+		if required, ok := s.DependentRequired[name]; ok {
+			for _, dependent := range required {
+				if _, ok := v[dependent]; !ok {
+					vl.fail(path+"/"+escapePointer(dependent), "dependentRequired", nil, "is required by %q", name)
+				}
+			}
+		}
+		if dependent, ok := s.DependentSchemas[name]; ok {
+			vl.validate(dependent, v, path)
+		}
+
+		prop, matched := s.Properties[name]
+		if matched {
+			vl.validate(prop, v[name], propPath)
+		}
+		for _, pattern := range patterns {
+			re, err := compilePattern(pattern)
+			if err != nil {
+				vl.fail(propPath, "patternProperties", nil, "cannot be matched with invalid pattern %q", pattern)
+				continue
+			}
+			if re.MatchString(name) {
+				matched = true
+				vl.validate(s.PatternProperties[pattern], v[name], propPath)
+			}
+		}
+		if matched {
+			continue
+		}
+
+		if isFalseSchema(s.AdditionalProperties) {
+			vl.fail(propPath, "additionalProperties", v[name], "is not allowed")
+		} else {
+			vl.validate(s.AdditionalProperties, v[name], propPath)
+		}
+	}
+}
+
+// resolve returns the schema of the reference within the root schema
+func (vl *schemaValidator) resolve(ref string) (*Schema, bool) {
+	if ref == "#" {
+		return vl.root, true
+	}
+	if !strings.HasPrefix(ref, "#/$defs/") {
+		return nil, false
+	}
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+	s, ok := vl.root.Defs[name]
+	return s, ok
+}
+
+// isFalseSchema reports whether the schema allows no value, e.g. the boolean
+// schema false
+func isFalseSchema(s *Schema) bool {
+	return s != nil && s.Not != nil && reflect.DeepEqual(*s.Not, Schema{})
+}
+
+// typeOf returns the JSON Schema type of the decoded value
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, ok := new(big.Float).SetString(string(v)); ok && f.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+func hasType(v interface{}, t string) bool {
+	actual := typeOf(v)
+	return actual == t || t == "number" && actual == "integer"
+}
+
+// normalizeValue converts the Go value to the decoded JSON value
+func normalizeValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var n interface{}
+	if err := d.Decode(&n); err != nil {
+		return v
+	}
+	return n
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if equalValues(normalizeValue(value), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares the decoded JSON values, numbers are equal by their
+// value, e.g. 1 and 1.0
+func equalValues(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Float).SetString(string(a))
+		y, okB := new(big.Float).SetString(string(b))
+		return okA && okB && x.Cmp(y) == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func encodeValues(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escapePointer escapes the name as the reference token of JSON Pointer
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// patterns caches the compiled patterns of the schemas
+var patterns sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
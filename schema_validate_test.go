@@ -0,0 +1,268 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+const userSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 5},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "maxItems": 3},
+		"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+		"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+		"manager": {"$ref": "#/$defs/ref"},
+		"a/b": {"const": 1}
+	},
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"$defs": {
+		"ref": {"oneOf": [{"type": "null"}, {"$ref": "#"}]}
+	}
+}`
+
+func TestSchemaValidate(t *testing.T) {
+	var schema jsonrpc.Schema
+	if err := json.Unmarshal([]byte(userSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value  string
+		expect []jsonrpc.Violation
+	}{
+		{value: `{"name":"ann","age":30}`},
+		{value: `{"name":"ann","age":30.0,"role":"user","tags":["a"],"point":[1,2.5],"manager":null,"a/b":1.0}`},
+		{value: `{"name":"ann","age":30,"manager":{"name":"bob","age":40,"manager":null}}`},
+		{value: `[]`, expect: []jsonrpc.Violation{{Path: "", Code: "type", Message: "expected object, got array"}}},
+		{value: `{}`, expect: []jsonrpc.Violation{
+			{Path: "/name", Code: "required", Message: "is required"},
+			{Path: "/age", Code: "required", Message: "is required"},
+		}},
+		{value: `{"name":"","age":-1.5,"x":1}`, expect: []jsonrpc.Violation{
+			{Path: "/age", Code: "type", Message: "expected integer, got number", Value: json.Number("-1.5")},
+			{Path: "/name", Code: "minLength", Message: "must be at least 1 characters long", Value: ""},
+			{Path: "/x", Code: "additionalProperties", Message: "is not allowed", Value: json.Number("1")},
+		}},
+		{value: `{"name":"žluťoučký","age":150,"role":"guest","email":"nobody"}`, expect: []jsonrpc.Violation{
+			{Path: "/age", Code: "exclusiveMaximum", Message: "must be less than 150", Value: json.Number("150")},
+			{Path: "/email", Code: "pattern", Message: `must match pattern "^[^@]+@[^@]+$"`, Value: "nobody"},
+			{Path: "/name", Code: "maxLength", Message: "must be at most 5 characters long", Value: "žluťoučký"},
+			{Path: "/role", Code: "enum", Message: `must be one of ["admin","user"]`, Value: "guest"},
+		}},
+		{value: `{"name":"ann","age":1,"tags":["a","b","a","c"],"point":[1,"2",3],"a/b":2}`, expect: []jsonrpc.Violation{
+			{Path: "/a~1b", Code: "const", Message: "must be 1", Value: json.Number("2")},
+			{Path: "/point/1", Code: "type", Message: "expected number, got string", Value: "2"},
+			{Path: "/point/2", Code: "items", Message: "is not allowed", Value: json.Number("3")},
+			{Path: "/tags", Code: "maxItems", Message: "must have at most 3 items"},
+			{Path: "/tags", Code: "uniqueItems", Message: "must have unique items, items 0 and 2 are equal"},
+		}},
+		{value: `{"name":"ann","age":1,"manager":{"name":"bob"}}`, expect: []jsonrpc.Violation{
+			{Path: "/manager", Code: "oneOf", Message: "must match exactly one of the schemas, matched 0"},
+		}},
+		{value: `{`, expect: []jsonrpc.Violation{{Path: "", Code: "parse", Message: "invalid JSON"}}},
+	}
+
+	for _, tt := range tests {
+		got := schema.Validate(json.RawMessage(tt.value))
+		if !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.value, tt.expect, got)
+		}
+	}
+}
+
+func TestSchemaValidateKeywords(t *testing.T) {
+	min, max := 1, 2
+	five, ten := 5.0, 10.0
+	tests := []struct {
+		schema  jsonrpc.Schema
+		value   string
+		message string
+	}{
+		{jsonrpc.Schema{MultipleOf: &five}, `12`, "must be a multiple of 5"},
+		{jsonrpc.Schema{Maximum: &ten}, `10.5`, "must be less than or equal to 10"},
+		{jsonrpc.Schema{ExclusiveMinimum: &five}, `5`, "must be greater than 5"},
+		{jsonrpc.Schema{MinItems: &max}, `[1]`, "must have at least 2 items"},
+		{jsonrpc.Schema{MinProperties: &max}, `{"a":1}`, "must have at least 2 properties"},
+		{jsonrpc.Schema{MaxProperties: &min}, `{"a":1,"b":2}`, "must have at most 1 properties"},
+		{jsonrpc.Schema{Not: &jsonrpc.Schema{Type: "string"}}, `"a"`, "must not match the schema"},
+		{jsonrpc.Schema{AnyOf: []*jsonrpc.Schema{{Type: "string"}, {Type: "boolean"}}}, `1`, "must match at least one of the schemas"},
+		{jsonrpc.Schema{AllOf: []*jsonrpc.Schema{{Type: "number"}, {Type: "integer"}}}, `1.5`, "expected integer, got number"},
+		{jsonrpc.Schema{Const: map[string]int{"a": 1}}, `{"a":2}`, `must be {"a":1}`},
+		{jsonrpc.Schema{Pattern: "("}, `"a"`, `cannot be matched with invalid pattern "("`},
+	}
+
+	for _, tt := range tests {
+		got := tt.schema.Validate(json.RawMessage(tt.value))
+		if len(got) != 1 || got[0].Message != tt.message {
+			t.Errorf("%s: expected %q, got %v", tt.value, tt.message, got)
+		}
+	}
+
+	if got := (&jsonrpc.Schema{Ref: "#/$defs/missing"}).Validate(json.RawMessage(`1`)); len(got) != 0 {
+		t.Errorf("Unresolved reference: expected no violations, got %v", got)
+	}
+}
+
+const applicatorsSchema = `{
+	"type": "object",
+	"properties": {"name": {"type": "string"}},
+	"patternProperties": {"^x-": {"type": "integer"}, "^(card|billing)$": true},
+	"additionalProperties": false,
+	"propertyNames": {"maxLength": 8},
+	"dependentRequired": {"card": ["billing"]},
+	"dependentSchemas": {"billing": {"properties": {"card": {"type": "string"}}}},
+	"if": {"properties": {"name": {"const": "admin"}}, "required": ["name"]},
+	"then": {"required": ["x-id"]},
+	"else": {"not": {"required": ["x-id"]}}
+}`
+
+func TestSchemaValidateApplicators(t *testing.T) {
+	var schema jsonrpc.Schema
+	if err := json.Unmarshal([]byte(applicatorsSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value  string
+		expect []jsonrpc.Violation
+	}{
+		{value: `{"name":"ann"}`},
+		{value: `{"name":"admin","x-id":1}`},
+		{value: `{"card":"1234","billing":"x"}`},
+		{value: `{"x-id":"1","additional":1}`, expect: []jsonrpc.Violation{
+			{Path: "/additional", Code: "propertyNames", Message: "is not a valid property name"},
+			{Path: "/additional", Code: "additionalProperties", Message: "is not allowed", Value: json.Number("1")},
+			{Path: "/x-id", Code: "type", Message: "expected integer, got string", Value: "1"},
+			{Path: "", Code: "not", Message: "must not match the schema"},
+		}},
+		{value: `{"name":"admin","card":1}`, expect: []jsonrpc.Violation{
+			{Path: "/billing", Code: "dependentRequired", Message: `is required by "card"`},
+			{Path: "/x-id", Code: "required", Message: "is required"},
+		}},
+		{value: `{"billing":"x","card":1}`, expect: []jsonrpc.Violation{
+			{Path: "/card", Code: "type", Message: "expected string, got integer", Value: json.Number("1")},
+		}},
+	}
+
+	for _, tt := range tests {
+		got := schema.Validate(json.RawMessage(tt.value))
+		if !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.value, tt.expect, got)
+		}
+	}
+}
+
+func TestSchemaValidateContains(t *testing.T) {
+	zero, two := 0, 2
+	integer := &jsonrpc.Schema{Type: "integer"}
+	tests := []struct {
+		schema  jsonrpc.Schema
+		value   string
+		message string
+	}{
+		{jsonrpc.Schema{Contains: integer}, `["a",1]`, ""},
+		{jsonrpc.Schema{Contains: integer}, `["a"]`, "must contain at least 1 matching items"},
+		{jsonrpc.Schema{Contains: integer, MinContains: &zero}, `["a"]`, ""},
+		{jsonrpc.Schema{Contains: integer, MinContains: &two}, `[1,"a"]`, "must contain at least 2 matching items"},
+		{jsonrpc.Schema{Contains: integer, MaxContains: &two}, `[1,2,3]`, "must contain at most 2 matching items"},
+	}
+
+	for _, tt := range tests {
+		got := tt.schema.Validate(json.RawMessage(tt.value))
+		switch {
+		case tt.message == "" && len(got) != 0:
+			t.Errorf("%s: expected no violations, got %v", tt.value, got)
+		case tt.message != "" && (len(got) != 1 || got[0].Message != tt.message):
+			t.Errorf("%s: expected %q, got %v", tt.value, tt.message, got)
+		}
+	}
+}
+
+func TestSchemaValidateConstNull(t *testing.T) {
+	var schema jsonrpc.Schema
+	if err := json.Unmarshal([]byte(`{"const":null}`), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if got := schema.Validate(json.RawMessage(`null`)); len(got) != 0 {
+		t.Errorf("null: expected no violations, got %v", got)
+	}
+	if got := schema.Validate(json.RawMessage(`0`)); len(got) != 1 || got[0].Message != "expected null, got integer" {
+		t.Errorf("0: expected violation of null, got %v", got)
+	}
+}
+
+func TestServerValidateParams(t *testing.T) {
+	var calls int
+	handler := HandlererFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		calls++
+		return json.RawMessage(`true`), nil, nil
+	})
+	type addRequest struct {
+		A int  `json:"a" validate:"required"`
+		B int  `json:"b" validate:"required"`
+		C *int `json:"c"`
+	}
+
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			"add":      jsonrpc.Describe(handler, jsonrpc.MethodDoc{Params: jsonrpc.SchemaOf(addRequest{})}),
+			"nodoc":    handler,
+			"optional": jsonrpc.Describe(handler, jsonrpc.MethodDoc{Params: &jsonrpc.Schema{Type: "object"}}),
+		},
+		jsonrpc.ServerValidateParams(),
+	)
+
+	tests := []struct {
+		body   string
+		expect string
+	}{
+		{
+			body:   `{"jsonrpc":"2.0","method":"add","params":{"a":1,"b":2},"id":1}`,
+			expect: `{"jsonrpc":"2.0","result":true,"id":1}`,
+		},
+		{
+			body:   `{"jsonrpc":"2.0","method":"add","params":{"a":1,"b":2,"c":null},"id":1}`,
+			expect: `{"jsonrpc":"2.0","result":true,"id":1}`,
+		},
+		{
+			body:   `{"jsonrpc":"2.0","method":"add","params":{"a":"1"},"id":1}`,
+			expect: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid method parameter(s)","data":{"violations":[{"path":"/b","code":"required","message":"is required"},{"path":"/a","code":"type","message":"expected integer, got string","value":"1"}]}},"id":1}`,
+		},
+		{
+			body:   `{"jsonrpc":"2.0","method":"add","id":1}`,
+			expect: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid method parameter(s)","data":{"violations":[{"path":"/a","code":"required","message":"is required"},{"path":"/b","code":"required","message":"is required"}]}},"id":1}`,
+		},
+		{
+			body:   `{"jsonrpc":"2.0","method":"optional","id":1}`,
+			expect: `{"jsonrpc":"2.0","result":true,"id":1}`,
+		},
+		{
+			body:   `{"jsonrpc":"2.0","method":"nodoc","params":["anything"],"id":1}`,
+			expect: `{"jsonrpc":"2.0","result":true,"id":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if got := strings.TrimSpace(w.Body.String()); got != tt.expect {
+			t.Errorf("%s: expected %s, got %s", tt.body, tt.expect, got)
+		}
+	}
+
+	if calls != 4 {
+		t.Errorf("Expected 4 calls of the handler, got %d", calls)
+	}
+}
@@ -1,20 +1,29 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/go-kit/kit/log"
 	httptransport "github.com/go-kit/kit/transport/http"
 )
 
 // Handlers maps the method to the proper handler
 type Handlers map[string]Handlerer
 
-// Set handler for given method, panics if method already exists
+// Set handler for given method, panics if method already exists or if it is
+// reserved, see ServerReservedMethod.
 func (h Handlers) Set(method string, handler Handlerer) {
+	if IsReservedMethod(method) {
+		panic(fmt.Sprintf("Method %s is reserved", method))
+	}
 	if _, ok := h[method]; ok {
 		panic(fmt.Sprintf("Handler for method %s already exists", method))
 	}
@@ -33,10 +42,21 @@ type ServerOption func(*Server)
 // whenever they're encountered in the processing of a request. Clients can
 // use this to provide custom error formatting and response codes. By default,
 // errors will be written with the DefaultErrorEncoder.
+//
+// The ErrorEncoder writes the whole HTTP response, so it is not used for the
+// errors of the batch elements, which are encoded as by DefaultErrorEncoder.
+// It is used for the errors of the batch itself, e.g. ParseError.
 func ServerErrorEncoder(ee httptransport.ErrorEncoder) ServerOption {
 	return func(s *Server) { s.errorEncoder = ee }
 }
 
+// ServerMaxBatchSize limits the number of requests in a batch, larger batches
+// are rejected with InvalidRequestError before any request is served. By
+// default, the size is not limited.
+func ServerMaxBatchSize(size int) ServerOption {
+	return func(s *Server) { s.maxBatchSize = size }
+}
+
 // NewServer constructs a new Server, which implements http.Handler
 func NewServer(
 	sh Handlers,
@@ -45,10 +65,19 @@ func NewServer(
 	s := &Server{
 		sh:           sh,
 		errorEncoder: DefaultErrorEncoder,
+		logger:       log.NewNopLogger(),
 	}
 	for _, option := range options {
 		option(s)
 	}
+	if s.authenticator == nil && (s.defaultPolicy != nil || len(s.methodPolicies) > 0) {
+		panic("Method policies require ServerAuthenticator")
+	}
+	handlers := s.reservedHandlers(sh)
+	if s.validateParams {
+		s.paramsSchemas = paramsSchemas(handlers)
+	}
+	s.sh = wrapHandlers(handlers, append(s.builtinMiddlewares(), s.middlewares...))
 	return s
 }
 
@@ -56,6 +85,55 @@ func NewServer(
 type Server struct {
 	sh           Handlers
 	errorEncoder httptransport.ErrorEncoder
+	maxBatchSize int
+	middlewares  []HandlerMiddleware
+	logger       log.Logger
+	debug        bool
+
+	timeout             time.Duration
+	methodTimeouts      map[string]time.Duration
+	clientTimeoutHeader string
+	clientTimeoutMax    time.Duration
+
+	bulkhead        *bulkhead
+	methodBulkheads map[string]*bulkhead
+
+	rateLimiter *rateLimiter
+
+	authenticator  Authenticator
+	defaultPolicy  *MethodPolicy
+	methodPolicies map[string]MethodPolicy
+
+	metrics  *Metrics
+	tracer   Tracer
+	recorder *Recorder
+
+	reserved      Handlers
+	introspection *OpenRPCDocument
+
+	validateParams bool
+	paramsSchemas  map[string]*Schema
+
+	translator Translator
+}
+
+// builtinMiddlewares returns the middlewares the Server applies to every
+// handler, outside of the ones set by ServerHandlerMiddleware. The panics are
+// recovered outermost, to cover the hooks of the builtin middlewares, and
+// innermost, as the timeoutMiddleware runs the handler in a goroutine.
+func (s *Server) builtinMiddlewares() []HandlerMiddleware {
+	return []HandlerMiddleware{
+		s.recoverMiddleware,
+		s.tracingMiddleware,
+		s.metricsMiddleware,
+		s.errorCauseMiddleware,
+		s.authMiddleware,
+		s.rateLimitMiddleware,
+		s.validateParamsMiddleware,
+		s.timeoutMiddleware,
+		s.bulkheadMiddleware,
+		s.recoverMiddleware,
+	}
 }
 
 // ServeHTTP implements http.Handler
@@ -67,65 +145,213 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.recorder != nil {
+		if err := s.recorder.serve(w, r, s.serve); err != nil {
+			s.logger.Log("err", err)
+		}
+		return
+	}
+
+	s.serve(w, r)
+}
+
+// serve serves the POST request
+func (s Server) serve(w http.ResponseWriter, r *http.Request) {
+
 	ctx := r.Context()
 	ctx = httptransport.PopulateRequestContext(ctx, r)
+	ctx = context.WithValue(ctx, ContextKeyRequestAcceptLanguage, r.Header.Get("Accept-Language"))
+	if s.translator != nil {
+		ctx = context.WithValue(ctx, contextKeyTranslator, s.translator)
+	}
+	if s.tracer != nil {
+		ctx = ExtractTraceContext(ctx, r.Header)
+	}
+
+	info := RequestInfo{
+		RemoteAddr: r.RemoteAddr,
+		ReceivedAt: time.Now(),
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.errorEncoder(ctx, NewError(ParseError), w)
+		return
+	}
+	ctx = context.WithValue(ctx, ContextKeyRequestBody, body)
+	if s.authenticator != nil {
+		ctx = context.WithValue(ctx, contextKeyAuthentication, &authentication{})
+	}
+
+	if isBatch(body) {
+		s.serveBatch(ctx, w, r.Header, body, info)
+		return
+	}
 
 	// Decode the body into an  object
 	var req Request
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.Unmarshal(body, &req)
 	if err != nil {
 		s.errorEncoder(ctx, NewError(ParseError), w)
 		return
 	}
 
-	ctx = PopulateRequestContext(ctx, &req)
+	ctx, res, err := s.serveRequest(ctx, r.Header, body, &req, info)
+	if err != nil {
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+
+	// notification
+	if res == nil {
+		httptransport.EncodeJSONResponse(ctx, w, NotificationResponse{})
+		return
+	}
+
+	httptransport.EncodeJSONResponse(ctx, w, res)
+}
 
-	if err := req.Validate(); err != nil {
+// serveBatch serves the batch of requests one by one and writes the array of
+// responses. Errors of the batch elements are encoded by errorResponse as the
+// ServerErrorEncoder writes a whole HTTP response, see ServerErrorEncoder.
+func (s Server) serveBatch(ctx context.Context, w http.ResponseWriter, requestHeader http.Header, body []byte, info RequestInfo) {
+	var msgs []json.RawMessage
+	if err := json.Unmarshal(body, &msgs); err != nil {
+		s.errorEncoder(ctx, NewError(ParseError), w)
+		return
+	}
+
+	if len(msgs) == 0 {
 		s.errorEncoder(ctx, NewError(InvalidRequestError), w)
 		return
 	}
+	if s.maxBatchSize > 0 && len(msgs) > s.maxBatchSize {
+		s.errorEncoder(ctx, NewError(InvalidRequestError, fmt.Sprintf("Batch exceeds %d requests", s.maxBatchSize)), w)
+		return
+	}
+
+	info.Batch = true
+	info.BatchSize = len(msgs)
+
+	if s.metrics != nil && s.metrics.BatchSize != nil {
+		s.metrics.BatchSize.Observe(float64(len(msgs)))
+	}
+
+	ctx, endSpan := s.startBatchSpan(ctx, len(msgs))
+	defer endSpan()
+
+	responses := BatchResponse{}
+	for i, msg := range msgs {
+		info.BatchIndex = i
+
+		reqCtx := ctx
+		var res *Response
+		var req Request
+		err := json.Unmarshal(msg, &req)
+		if err != nil {
+			err = NewError(InvalidRequestError)
+		} else {
+			reqCtx, res, err = s.serveRequest(ctx, requestHeader, msg, &req, info)
+		}
+
+		if err != nil {
+			res = errorResponse(reqCtx, err)
+		}
+		if res != nil {
+			responses = append(responses, *res)
+		}
+	}
+
+	// batch of notifications
+	if len(responses) == 0 {
+		httptransport.EncodeJSONResponse(ctx, w, NotificationResponse{})
+		return
+	}
+
+	httptransport.EncodeJSONResponse(ctx, w, responses)
+}
+
+// serveRequest validates the request and serves it by the handler of the
+// method. It returns nil Response for notifications. The returned context is
+// populated with the request values.
+func (s Server) serveRequest(ctx context.Context, requestHeader http.Header, msg json.RawMessage, req *Request, info RequestInfo) (context.Context, *Response, error) {
+	info.Method = req.Method
+	info.ID = req.ID
+	info.Notification = req.ID == nil
+
+	ctx = PopulateRequestContext(ctx, req)
+	ctx = context.WithValue(ctx, ContextKeyRequestMessage, msg)
+	ctx = context.WithValue(ctx, ContextKeyRequestInfo, info)
+
+	_, registered := s.sh[req.Method]
+	if err := req.validate(registered); err != nil {
+		return ctx, nil, NewError(InvalidRequestError)
+	}
 
 	// Get the endpoint and codecs from the map using the method
 	// defined in the JSON  object
 	srv, ok := s.sh[req.Method]
 	if !ok {
-		s.errorEncoder(ctx, NewError(MethodNotFoundError), w)
-		return
+		return ctx, nil, NewError(MethodNotFoundError)
 	}
 
 	// notification
 	if req.ID == nil {
-		go srv.ServeJSONRPC(ctx, r.Header, req.Params)
-		httptransport.EncodeJSONResponse(ctx, w, NotificationResponse{})
-		return
+		go s.serveNotification(detachedContext{ctx}, srv, requestHeader, req.Params)
+		return ctx, nil, nil
 	}
 
-	resp, respHeaders, err := srv.ServeJSONRPC(ctx, r.Header, req.Params)
-
+	resp, respHeaders, err := srv.ServeJSONRPC(ctx, requestHeader, req.Params)
 	if err != nil {
-		s.errorEncoder(ctx, err, w)
-		return
+		return ctx, nil, err
 	}
 
-	res := Response{
+	res := &Response{
 		RespHeaders: respHeaders,
 		JSONRPC:     Version,
 		ID:          req.ID,
 	}
 
-	var respErr Error
-	err = json.Unmarshal(resp, &respErr)
 	// try to find out if resp is an error
-	if err == nil && respErr.ErrorCode() != 0 {
+	if respErr, ok := responseError(resp); ok {
+		respErr = localizeError(ctx, respErr)
+		res.Error = &respErr
+	} else {
 		// it has to set a pointer otherwise in Go 1.7 base64 encoded string is returned.
 		// In Go 1.8 works as expected
 		// Golang release notes 1.8: A RawMessage value now marshals the same as its pointer type.
-		res.Error = &respErr
-	} else {
 		res.Result = &resp
 	}
 
-	httptransport.EncodeJSONResponse(ctx, w, res)
+	return ctx, res, nil
+}
+
+// responseError decodes the Error if the handler encoded one as the response
+func responseError(resp json.RawMessage) (Error, bool) {
+	var respErr Error
+	err := json.Unmarshal(resp, &respErr)
+	return respErr, err == nil && respErr.ErrorCode() != 0
+}
+
+// serveNotification serves the notification, the response is discarded
+func (s Server) serveNotification(ctx context.Context, srv Handlerer, requestHeader http.Header, params json.RawMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logPanic(ctx, r)
+		}
+	}()
+
+	if s.metrics != nil && s.metrics.NotificationQueue != nil {
+		s.metrics.NotificationQueue.Add(1)
+		defer s.metrics.NotificationQueue.Add(-1)
+	}
+	srv.ServeJSONRPC(ctx, requestHeader, params)
+}
+
+// isBatch reports whether the body holds an array of requests
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
 }
 
 // DefaultErrorEncoder writes the error to the ResponseWriter,
@@ -133,26 +359,53 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // The Error() string of the error will be used as the response error message.
 // If the error implements ErrorCoder, the provided code will be set on the
 // response error.
+// If the error is or wraps an Error, it is encoded as is, including its Data.
+// The default message of the code is translated, see ServerTranslator.
 // If the error implements Headerer, the given headers will be set.
 func DefaultErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", ContentType)
-	e := NewError(InternalError)
-	if te, ok := err.(ErrorCoder); ok {
-		e.Code = te.ErrorCode()
-	}
 
-	if te, ok := err.(Errorer); ok {
-		e.Message = te.Error()
+	res := errorResponse(ctx, err)
+	for k, values := range res.RespHeaders {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
 	}
 
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
 
-	reqID, _ := ctx.Value(ContextKeyRequestID).(*RequestID)
-	json.NewEncoder(w).Encode(Response{
-		ID:      reqID,
-		JSONRPC: Version,
-		Error:   &e,
-	})
+// errorResponse converts the error to the Response as described by
+// DefaultErrorEncoder.
+func errorResponse(ctx context.Context, err error) *Response {
+	e := NewError(InternalError)
+	if te := (Error{}); errors.As(err, &te) {
+		e = te
+	} else {
+		if te, ok := err.(ErrorCoder); ok {
+			e.Code = te.ErrorCode()
+		}
+
+		if te, ok := err.(Errorer); ok {
+			e.Message = te.Error()
+		}
+	}
+
+	e = localizeError(ctx, e)
+
+	var header http.Header
+	if headerer, ok := err.(Headerer); ok {
+		header = headerer.Headers()
+	}
+
+	reqID, _ := RequestIDFromContext(ctx)
+	return &Response{
+		ID:          reqID,
+		JSONRPC:     Version,
+		Error:       &e,
+		RespHeaders: header,
+	}
 }
 
 // Headerer is checked by DefaultErrorEncoder. If an error value implements
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
 )
@@ -296,3 +297,122 @@ func TestNotificationRequest(t *testing.T) {
 		t.Errorf("Expected status code '%v', got '%v'", expect, got)
 	}
 }
+
+func TestServerBatch(t *testing.T) {
+	notified := make(chan [2]int, 1)
+
+	server := jsonrpc.NewServer(jsonrpc.Handlers{
+		testMethodName: HandlererFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+			index, size, ok := jsonrpc.BatchInfo(ctx)
+			if !ok {
+				t.Error("Expecting call to be part of a batch")
+			}
+			switch string(params) {
+			case `"notify"`:
+				notified <- [2]int{index, size}
+				return nil, nil, nil
+			case `"fail"`:
+				return nil, nil, jsonrpc.NewInvalidParamsError()
+			}
+			hdr := http.Header{}
+			hdr.Set("X-Params", string(params))
+			return params, hdr, nil
+		}),
+	})
+
+	body := fmt.Sprintf(`[
+		{"jsonrpc":"2.0","method":"%[1]s","params":0,"id":"a"},
+		{"jsonrpc":"2.0","method":"%[1]s","params":"notify"},
+		{"jsonrpc":"2.0","method":"%[1]s","params":"fail","id":"b"},
+		1,
+		{"jsonrpc":"2.0","method":"unknown","id":"c"}
+	]`, testMethodName)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	expect := `[` +
+		`{"jsonrpc":"2.0","result":0,"id":"a"},` +
+		`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid method parameter(s)"},"id":"b"},` +
+		`{"jsonrpc":"2.0","error":{"code":-32600,"message":"The JSON sent is not a valid Request object"}},` +
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"The method does not exist / is not available"},"id":"c"}` +
+		`]`
+	if got := strings.TrimSpace(rw.Body.String()); got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+	if got, expect := rw.Header().Get("X-Params"), "0"; got != expect {
+		t.Errorf("Expected header %s, got %s", expect, got)
+	}
+
+	select {
+	case got := <-notified:
+		if got != [2]int{1, 5} {
+			t.Errorf("Expected notification batch info [1 5], got %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+}
+
+func TestServerBatchSpecialCases(t *testing.T) {
+	cases := []struct {
+		body       string
+		statusCode int
+		expect     string
+	}{
+		{`[]`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"The JSON sent is not a valid Request object"}}`},
+		{`[{"jsonrpc":"2.0","method":"test"}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"An error occurred on the server while parsing the JSON text"}}`},
+		{` [{"jsonrpc":"2.0","method":"test"},{"jsonrpc":"2.0","method":"test"}]`, http.StatusNoContent, ``},
+	}
+
+	for _, c := range cases {
+		server := jsonrpc.NewServer(jsonrpc.Handlers{testMethodName: HandlererFunc(nopHandler)})
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(c.body))
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+
+		if got := rw.Code; got != c.statusCode {
+			t.Errorf("TC(%s) Expected status code %d, got %d", c.body, c.statusCode, got)
+		}
+		if got := strings.TrimSpace(rw.Body.String()); got != c.expect {
+			t.Errorf("TC(%s) Expected body '%s', got '%s'", c.body, c.expect, got)
+		}
+	}
+}
+
+func TestServerMaxBatchSize(t *testing.T) {
+	var calls int
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			calls++
+			return nil, nil, nil
+		})},
+		jsonrpc.ServerMaxBatchSize(2),
+	)
+
+	cases := []struct {
+		size   int
+		expect string
+	}{
+		{2, `[{"jsonrpc":"2.0","result":null,"id":0},{"jsonrpc":"2.0","result":null,"id":1}]`},
+		{3, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Batch exceeds 2 requests"}}`},
+	}
+
+	for _, c := range cases {
+		msgs := make([]string, c.size)
+		for i := range msgs {
+			msgs[i] = fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":%d}`, testMethodName, i)
+		}
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("["+strings.Join(msgs, ",")+"]"))
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+
+		if got := strings.TrimSpace(rw.Body.String()); got != c.expect {
+			t.Errorf("TC(%d) Expected body '%s', got '%s'", c.size, c.expect, got)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
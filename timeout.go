@@ -0,0 +1,127 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ServerTimeout sets the default execution timeout of every method. The
+// timeout is applied to the context passed to Handlerer. By default, there is
+// no timeout.
+func ServerTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) { s.timeout = timeout }
+}
+
+// ServerMethodTimeout sets the execution timeout of the method, overriding the
+// default set by ServerTimeout.
+func ServerMethodTimeout(method string, timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if s.methodTimeouts == nil {
+			s.methodTimeouts = map[string]time.Duration{}
+		}
+		s.methodTimeouts[method] = timeout
+	}
+}
+
+// ServerClientTimeout honors the timeout requested by the client in the given
+// request header, e.g. X-Request-Timeout. The value is either a duration
+// ("1.5s", "300ms") or a number of seconds. The requested timeout is capped by
+// max, unless max is zero, and by the method timeout.
+func ServerClientTimeout(header string, max time.Duration) ServerOption {
+	return func(s *Server) {
+		s.clientTimeoutHeader = header
+		s.clientTimeoutMax = max
+	}
+}
+
+// callTimeout returns the timeout for the method call, zero means none.
+func (s *Server) callTimeout(method string, requestHeader http.Header) time.Duration {
+	timeout := s.timeout
+	if t, ok := s.methodTimeouts[method]; ok {
+		timeout = t
+	}
+
+	if s.clientTimeoutHeader == "" {
+		return timeout
+	}
+
+	client, ok := parseTimeout(requestHeader.Get(s.clientTimeoutHeader))
+	if !ok {
+		return timeout
+	}
+	if s.clientTimeoutMax > 0 && client > s.clientTimeoutMax {
+		client = s.clientTimeoutMax
+	}
+	if timeout > 0 && client > timeout {
+		return timeout
+	}
+	return client
+}
+
+// parseTimeout parses the duration or number of seconds
+func parseTimeout(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d, true
+	}
+	if sec, err := strconv.ParseFloat(v, 64); err == nil && sec > 0 {
+		return time.Duration(sec * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// timeoutMiddleware applies the call timeout to the context and returns
+// TimeoutError once it expires, without waiting for the next Handlerer.
+func (s *Server) timeoutMiddleware(next Handlerer) Handlerer {
+	return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		method, _ := MethodFromContext(ctx)
+		timeout := s.callTimeout(method, requestHeader)
+		if timeout <= 0 {
+			return next.ServeJSONRPC(ctx, requestHeader, params)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		type result struct {
+			response       json.RawMessage
+			responseHeader http.Header
+			err            error
+		}
+
+		done := make(chan result, 1)
+		go func() {
+			var r result
+			r.response, r.responseHeader, r.err = next.ServeJSONRPC(ctx, requestHeader, params)
+			done <- r
+		}()
+
+		select {
+		case r := <-done:
+			if r.err != nil && ctx.Err() == context.DeadlineExceeded {
+				return nil, nil, NewTimeoutError()
+			}
+			return r.response, r.responseHeader, r.err
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, nil, NewTimeoutError()
+			}
+			return nil, nil, NewCancelledError()
+		}
+	})
+}
+
+// detachedContext keeps the values of the parent context, but it is never
+// canceled. It is used for notifications, which outlive the HTTP request.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
@@ -0,0 +1,127 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func blockingHandler(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+func deadlineHandler(deadline chan<- time.Duration) HandlererFunc {
+	return func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+		d, ok := ctx.Deadline()
+		if !ok {
+			deadline <- 0
+		} else {
+			deadline <- d.Sub(time.Now())
+		}
+		return json.RawMessage(`true`), nil, nil
+	}
+}
+
+func serveTestMethod(server http.Handler, header http.Header, notification bool) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s","id":1}`, testMethodName)
+	if notification {
+		body = fmt.Sprintf(`{"jsonrpc":"2.0","method":"%s"}`, testMethodName)
+	}
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+	return rw
+}
+
+func TestServerTimeout(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(blockingHandler)},
+		jsonrpc.ServerTimeout(time.Hour),
+		jsonrpc.ServerMethodTimeout(testMethodName, 10*time.Millisecond),
+	)
+
+	rw := serveTestMethod(server, nil, false)
+
+	expect := fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":%d,"message":"Request timed out"},"id":1}`, jsonrpc.TimeoutError)
+	if got := strings.TrimSpace(rw.Body.String()); got != expect {
+		t.Errorf("Expected body '%s', got '%s'", expect, got)
+	}
+}
+
+func TestServerTimeoutDoesNotWaitForHandler(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+			<-release
+			return json.RawMessage(`true`), nil, nil
+		})},
+		jsonrpc.ServerTimeout(10*time.Millisecond),
+	)
+
+	rw := serveTestMethod(server, nil, false)
+
+	if !strings.Contains(rw.Body.String(), fmt.Sprintf(`"code":%d`, jsonrpc.TimeoutError)) {
+		t.Errorf("Expected timeout error, got %s", rw.Body.String())
+	}
+}
+
+func TestServerClientTimeout(t *testing.T) {
+	cases := []struct {
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"", 50 * time.Second, time.Minute},
+		{"invalid", 50 * time.Second, time.Minute},
+		{"2s", time.Second, 2 * time.Second},
+		{"0.5", 100 * time.Millisecond, 500 * time.Millisecond},
+		{"1h", 20 * time.Second, 30 * time.Second},
+	}
+
+	for _, c := range cases {
+		deadline := make(chan time.Duration, 1)
+		server := jsonrpc.NewServer(
+			jsonrpc.Handlers{testMethodName: deadlineHandler(deadline)},
+			jsonrpc.ServerTimeout(time.Minute),
+			jsonrpc.ServerClientTimeout("X-Request-Timeout", 30*time.Second),
+		)
+
+		serveTestMethod(server, http.Header{"X-Request-Timeout": []string{c.header}}, false)
+
+		if got := <-deadline; got < c.min || got > c.max {
+			t.Errorf("TC(%s) Expected deadline between %s and %s, got %s", c.header, c.min, c.max, got)
+		}
+	}
+}
+
+func TestServerNotificationTimeout(t *testing.T) {
+	deadline := make(chan time.Duration, 1)
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{testMethodName: deadlineHandler(deadline)},
+		jsonrpc.ServerTimeout(time.Minute),
+	)
+
+	serveTestMethod(server, nil, true)
+
+	select {
+	case got := <-deadline:
+		if got <= 50*time.Second || got > time.Minute {
+			t.Errorf("Expected notification deadline about a minute, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+}
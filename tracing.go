@@ -0,0 +1,215 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+const (
+	// HeaderTraceParent carries the W3C Trace Context of the caller
+	HeaderTraceParent = "Traceparent"
+
+	// HeaderTraceState carries the vendor specific W3C Trace Context
+	HeaderTraceState = "Tracestate"
+)
+
+// SpanContext identifies a span as defined by W3C Trace Context
+// https://www.w3.org/TR/trace-context/
+type SpanContext struct {
+	TraceID    string
+	SpanID     string
+	Sampled    bool
+	TraceState string
+}
+
+// IsValid reports whether the trace and span ids are set
+func (sc SpanContext) IsValid() bool {
+	return isHexID(sc.TraceID, 32) && isHexID(sc.SpanID, 16)
+}
+
+// TraceParent returns the value of the traceparent header
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceParent parses the value of the traceparent header
+func ParseTraceParent(v string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	sc := SpanContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+		Sampled: flags[0]&1 == 1,
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// isHexID reports whether the id is lowercase hex of the length, not all zeros
+func isHexID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ContextWithSpanContext stores the SpanContext of the current span in the
+// context.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKeySpanContext, sc)
+}
+
+// SpanContextFromContext returns the SpanContext of the current span. On the
+// server it is the remote parent until a span is started.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(contextKeySpanContext).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// ExtractTraceContext stores the SpanContext from the W3C Trace Context
+// headers in the context.
+func ExtractTraceContext(ctx context.Context, header http.Header) context.Context {
+	sc, ok := ParseTraceParent(header.Get(HeaderTraceParent))
+	if !ok {
+		return ctx
+	}
+	sc.TraceState = header.Get(HeaderTraceState)
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// InjectTraceContext sets the W3C Trace Context headers from the SpanContext
+// in the context.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	header.Set(HeaderTraceParent, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(HeaderTraceState, sc.TraceState)
+	}
+}
+
+// ClientTraceContext is a RequestFunc for go-kit HTTP client which injects
+// the W3C Trace Context of the current span into the outgoing request.
+func ClientTraceContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		InjectTraceContext(ctx, r.Header)
+		return ctx
+	}
+}
+
+// Tracer starts spans. It is implemented by adapters of tracing libraries,
+// e.g. OpenTelemetry. The parent span is SpanContextFromContext.
+type Tracer interface {
+	Start(ctx context.Context, name string) Span
+}
+
+// Span is a unit of work traced by Tracer
+type Span interface {
+	// SpanContext returns the context propagated to the children of the span.
+	SpanContext() SpanContext
+
+	// SetAttribute sets the attribute of the span. The keys follow the
+	// OpenTelemetry semantic conventions for JSON-RPC.
+	SetAttribute(key string, value interface{})
+
+	// End completes the span, err is the error of the traced work, if any.
+	End(err error)
+}
+
+// ServerTracer traces every call with a span named after the method. The
+// parent is extracted from the W3C Trace Context headers. Batches are traced
+// with a "jsonrpc.batch" span, the parent of the spans of its elements.
+func ServerTracer(t Tracer) ServerOption {
+	return func(s *Server) { s.tracer = t }
+}
+
+// TracingMiddleware traces the calls of the next Handlerer. It can be used
+// on Handler without Server, then the parent span has to be set with
+// ContextWithSpanContext or ExtractTraceContext.
+func TracingMiddleware(t Tracer) HandlerMiddleware {
+	return func(next Handlerer) Handlerer {
+		return HandlerFunc(func(ctx context.Context, requestHeader http.Header, params json.RawMessage) (response json.RawMessage, responseHeader http.Header, err error) {
+			method, _ := MethodFromContext(ctx)
+			span := t.Start(ctx, method)
+			ctx = ContextWithSpanContext(ctx, span.SpanContext())
+
+			span.SetAttribute("rpc.system", "jsonrpc")
+			span.SetAttribute("rpc.method", method)
+			span.SetAttribute("rpc.jsonrpc.version", Version)
+			if id, ok := RequestIDFromContext(ctx); ok {
+				span.SetAttribute("rpc.jsonrpc.request_id", formatRequestID(id))
+			}
+			span.SetAttribute("rpc.jsonrpc.notification", IsNotification(ctx))
+			if index, _, ok := BatchInfo(ctx); ok {
+				span.SetAttribute("rpc.jsonrpc.batch_index", index)
+			}
+
+			defer func() {
+				var e *Error
+				if err != nil {
+					e = errorResponse(ctx, err).Error
+				} else if re, ok := responseError(response); ok {
+					e = &re
+				}
+				if e != nil {
+					span.SetAttribute("rpc.jsonrpc.error_code", e.Code)
+					span.SetAttribute("rpc.jsonrpc.error_message", e.Message)
+					span.End(e)
+					return
+				}
+				span.End(nil)
+			}()
+
+			return next.ServeJSONRPC(ctx, requestHeader, params)
+		})
+	}
+}
+
+// tracingMiddleware applies TracingMiddleware if ServerTracer is set
+func (s *Server) tracingMiddleware(next Handlerer) Handlerer {
+	if s.tracer == nil {
+		return next
+	}
+	return TracingMiddleware(s.tracer)(next)
+}
+
+// startBatchSpan starts the span of the batch if ServerTracer is set
+func (s Server) startBatchSpan(ctx context.Context, size int) (context.Context, func()) {
+	if s.tracer == nil {
+		return ctx, func() {}
+	}
+	span := s.tracer.Start(ctx, "jsonrpc.batch")
+	span.SetAttribute("rpc.system", "jsonrpc")
+	span.SetAttribute("rpc.jsonrpc.batch_size", size)
+	return ContextWithSpanContext(ctx, span.SpanContext()), func() { span.End(nil) }
+}
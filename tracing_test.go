@@ -0,0 +1,167 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type testSpan struct {
+	name   string
+	parent jsonrpc.SpanContext
+	sc     jsonrpc.SpanContext
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SpanContext() jsonrpc.SpanContext           { return s.sc }
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) End(err error)                              { s.err, s.ended = err, true }
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) jsonrpc.Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent, _ := jsonrpc.SpanContextFromContext(ctx)
+	traceID := parent.TraceID
+	if traceID == "" {
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	}
+	span := &testSpan{
+		name:   name,
+		parent: parent,
+		sc:     jsonrpc.SpanContext{TraceID: traceID, SpanID: fmt.Sprintf("%016x", len(t.spans)+1), Sampled: true},
+		attrs:  map[string]interface{}{},
+	}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestParseTraceParent(t *testing.T) {
+	cases := []struct {
+		value string
+		valid bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		sc, ok := jsonrpc.ParseTraceParent(c.value)
+		if ok != c.valid {
+			t.Errorf("TC(%s) Expected valid %v, got %v", c.value, c.valid, ok)
+		}
+		if ok && strings.HasPrefix(c.value, "00") && sc.TraceParent() != c.value {
+			t.Errorf("TC(%s) Expected round trip, got %s", c.value, sc.TraceParent())
+		}
+	}
+}
+
+func TestServerTracer(t *testing.T) {
+	tracer := &testTracer{}
+	var injected string
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			testMethodName: HandlererFunc(func(ctx context.Context, _ http.Header, params json.RawMessage) (json.RawMessage, http.Header, error) {
+				header := http.Header{}
+				jsonrpc.InjectTraceContext(ctx, header)
+				injected = header.Get("traceparent")
+				if string(params) == `"fail"` {
+					return nil, nil, jsonrpc.NewInvalidParamsError()
+				}
+				return json.RawMessage(`true`), nil, nil
+			}),
+		},
+		jsonrpc.ServerTracer(tracer),
+	)
+
+	body := fmt.Sprintf(`[{"jsonrpc":"2.0","method":"%[1]s","id":1},{"jsonrpc":"2.0","method":"%[1]s","params":"fail","id":"b"}]`, testMethodName)
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got, expect := len(tracer.spans), 3; got != expect {
+		t.Fatalf("Expected %d spans, got %d", expect, got)
+	}
+
+	batch, first, second := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if got, expect := batch.name, "jsonrpc.batch"; got != expect {
+		t.Errorf("Expected span %s, got %s", expect, got)
+	}
+	if got, expect := batch.parent.SpanID, "b7ad6b7169203331"; got != expect {
+		t.Errorf("Expected remote parent %s, got %s", expect, got)
+	}
+	if got, expect := batch.attrs["rpc.jsonrpc.batch_size"], 2; got != expect {
+		t.Errorf("Expected batch size %v, got %v", expect, got)
+	}
+
+	for _, span := range []*testSpan{first, second} {
+		if got, expect := span.name, testMethodName; got != expect {
+			t.Errorf("Expected span %s, got %s", expect, got)
+		}
+		if got, expect := span.parent.SpanID, batch.sc.SpanID; got != expect {
+			t.Errorf("Expected parent %s, got %s", expect, got)
+		}
+		if !span.ended {
+			t.Errorf("Expected span %s to be ended", span.sc.SpanID)
+		}
+		if got, expect := span.attrs["rpc.jsonrpc.notification"], false; got != expect {
+			t.Errorf("Expected notification %v, got %v", expect, got)
+		}
+	}
+
+	if got, expect := first.attrs["rpc.jsonrpc.request_id"], "1"; got != expect {
+		t.Errorf("Expected request id %v, got %v", expect, got)
+	}
+	if first.err != nil {
+		t.Errorf("Expected no error, got %s", first.err)
+	}
+	if got, expect := second.attrs["rpc.jsonrpc.request_id"], "b"; got != expect {
+		t.Errorf("Expected request id %v, got %v", expect, got)
+	}
+	if got, expect := second.attrs["rpc.jsonrpc.error_code"], jsonrpc.InvalidParamsError; got != expect {
+		t.Errorf("Expected error code %v, got %v", expect, got)
+	}
+	if second.err == nil {
+		t.Error("Expected span error")
+	}
+
+	if got, expect := injected, second.sc.TraceParent(); got != expect {
+		t.Errorf("Expected injected traceparent %s, got %s", expect, got)
+	}
+}
+
+func TestClientTraceContext(t *testing.T) {
+	sc := jsonrpc.SpanContext{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331", TraceState: "a=b"}
+	ctx := jsonrpc.ContextWithSpanContext(context.Background(), sc)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", nil)
+	jsonrpc.ClientTraceContext()(ctx, r)
+
+	if got, expect := r.Header.Get("Traceparent"), "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00"; got != expect {
+		t.Errorf("Expected traceparent %s, got %s", expect, got)
+	}
+	if got, expect := r.Header.Get("Tracestate"), "a=b"; got != expect {
+		t.Errorf("Expected tracestate %s, got %s", expect, got)
+	}
+}
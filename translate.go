@@ -0,0 +1,174 @@
+package jsonrpc
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Translator translates the default messages of the error codes, see
+// ErrorMessage. The lang is the lowercase language tag, e.g. "de" or "pt-br".
+// It returns false if there is no translation of the code to the language.
+type Translator interface {
+	Translate(lang string, code int) (string, bool)
+}
+
+// TranslatorFunc is an adapter to allow the use of ordinary functions as
+// Translator.
+type TranslatorFunc func(lang string, code int) (string, bool)
+
+// Translate implements Translator
+func (f TranslatorFunc) Translate(lang string, code int) (string, bool) {
+	return f(lang, code)
+}
+
+// Catalog is the Translator of the messages added for the languages, e.g.
+//
+//	catalog := jsonrpc.NewCatalog()
+//	catalog.Add("de", jsonrpc.MethodNotFoundError, "Die Methode existiert nicht")
+//	catalog.Add("de", ErrorCodeNotFound, "Nicht gefunden")
+//
+// It is safe for concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[int]string
+}
+
+// NewCatalog returns the empty Catalog
+func NewCatalog() *Catalog {
+	return &Catalog{messages: map[string]map[int]string{}}
+}
+
+// Add adds the message of the code in the language, it replaces the message
+// added before
+func (c *Catalog) Add(lang string, code int, message string) *Catalog {
+	lang = strings.ToLower(lang)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = map[int]string{}
+	}
+	c.messages[lang][code] = message
+	return c
+}
+
+// Translate implements Translator
+func (c *Catalog) Translate(lang string, code int) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	message, ok := c.messages[lang][code]
+	return message, ok
+}
+
+// ServerTranslator sets the Translator of the messages of the errors. The
+// default message of the code is replaced by its translation to the most
+// preferred language of the Accept-Language header which has one, e.g. for
+// "de-AT, fr;q=0.8" the translations to "de-at", "de" and "fr" are looked up
+// in this order. The message is translated whenever it equals the default
+// message of the code, also if it is set by the application, e.g. by
+// NewError(code, message), or the error is encoded as the response of the
+// handler. Other messages are not translated, the handlers can localize them
+// using AcceptLanguageFromContext and ErrorMessageContext.
+func ServerTranslator(t Translator) ServerOption {
+	return func(s *Server) { s.translator = t }
+}
+
+// AcceptLanguageFromContext returns the Accept-Language header of the HTTP
+// request stored in the context by Server
+func AcceptLanguageFromContext(ctx context.Context) (string, bool) {
+	header, ok := ctx.Value(ContextKeyRequestAcceptLanguage).(string)
+	return header, ok && header != ""
+}
+
+// ErrorMessageContext returns the message of the code translated by the
+// Translator of the Server to the language of the request, see
+// ServerTranslator. It returns ErrorMessage if there is no translation.
+func ErrorMessageContext(ctx context.Context, code int) string {
+	if message, ok := translateErrorMessage(ctx, code); ok {
+		return message
+	}
+	return ErrorMessage(code)
+}
+
+// localizeError translates the message of the Error if it is the default
+// message of the code
+func localizeError(ctx context.Context, e Error) Error {
+	if e.Message != ErrorMessage(e.Code) {
+		return e
+	}
+	if message, ok := translateErrorMessage(ctx, e.Code); ok {
+		e.Message = message
+	}
+	return e
+}
+
+func translateErrorMessage(ctx context.Context, code int) (string, bool) {
+	t, ok := ctx.Value(contextKeyTranslator).(Translator)
+	if !ok {
+		return "", false
+	}
+	header, ok := AcceptLanguageFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, lang := range acceptedLanguages(header) {
+		for {
+			if message, ok := t.Translate(lang, code); ok {
+				return message, true
+			}
+			i := strings.LastIndex(lang, "-")
+			if i == -1 {
+				break
+			}
+			lang = lang[:i]
+		}
+	}
+	return "", false
+}
+
+// acceptedLanguages returns the lowercase language tags of the Accept-Language
+// header ordered by the preference, the wildcard and the tags with zero
+// quality are omitted
+func acceptedLanguages(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil {
+				q = 0
+			}
+			quality = q
+		}
+		if quality <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag, quality})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
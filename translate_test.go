@@ -0,0 +1,120 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+func newTestCatalog() *jsonrpc.Catalog {
+	return jsonrpc.NewCatalog().
+		Add("de", jsonrpc.MethodNotFoundError, "Die Methode existiert nicht").
+		Add("fr", jsonrpc.MethodNotFoundError, "La méthode n'existe pas").
+		Add("es", jsonrpc.MethodNotFoundError, "El método no existe").
+		Add("it", jsonrpc.MethodNotFoundError, "Il metodo non esiste").
+		Add("pt-BR", jsonrpc.MethodNotFoundError, "O método não existe").
+		Add("ja", jsonrpc.MethodNotFoundError, "メソッドが存在しません").
+		Add("de", errorCodeNotFound, "Nicht gefunden").
+		Add("de", jsonrpc.InvalidParamsError, "Ungültige Parameter")
+}
+
+func encodedNotFoundHandler(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+	resp, err := json.Marshal(jsonrpc.NewError(errorCodeNotFound))
+	return resp, nil, err
+}
+
+func TestServerTranslator(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{
+			"find": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				return nil, nil, jsonrpc.NewError(errorCodeNotFound)
+			}),
+			"custom": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				return nil, nil, jsonrpc.NewInvalidParamsError("name is required")
+			}),
+			"explicit": HandlererFunc(func(context.Context, http.Header, json.RawMessage) (json.RawMessage, http.Header, error) {
+				return nil, nil, jsonrpc.NewError(errorCodeNotFound, jsonrpc.ErrorMessage(errorCodeNotFound))
+			}),
+			"encoded": HandlererFunc(encodedNotFoundHandler),
+			"context": HandlererFunc(func(ctx context.Context, _ http.Header, _ json.RawMessage) (json.RawMessage, http.Header, error) {
+				return nil, nil, jsonrpc.NewError(jsonrpc.InvalidParamsError, jsonrpc.ErrorMessageContext(ctx, jsonrpc.InvalidParamsError)+": name")
+			}),
+		},
+		jsonrpc.ServerTranslator(newTestCatalog()),
+	)
+
+	tests := []struct {
+		body           string
+		acceptLanguage string
+		expect         string
+	}{
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "de-AT, fr;q=0.8", `{"code":-32601,"message":"Die Methode existiert nicht"}`},
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "de;q=0.5, fr", `{"code":-32601,"message":"La méthode n'existe pas"}`},
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "pt-br", `{"code":-32601,"message":"O método não existe"}`},
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "ja-JP", `{"code":-32601,"message":"メソッドが存在しません"}`},
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "pt", `{"code":-32601,"message":"The method does not exist / is not available"}`},
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "cs, de;q=0", `{"code":-32601,"message":"The method does not exist / is not available"}`},
+		{`{"jsonrpc":"2.0","method":"missing","id":1}`, "", `{"code":-32601,"message":"The method does not exist / is not available"}`},
+		{`{"jsonrpc":"2.0","method":"find","id":1}`, "de", `{"code":4040,"message":"Nicht gefunden"}`},
+		{`{"jsonrpc":"2.0","method":"custom","id":1}`, "de", `{"code":-32602,"message":"name is required"}`},
+		{`{"jsonrpc":"2.0","method":"explicit","id":1}`, "de", `{"code":4040,"message":"Nicht gefunden"}`},
+		{`{"jsonrpc":"2.0","method":"encoded","id":1}`, "de", `{"code":4040,"message":"Nicht gefunden"}`},
+		{`{"jsonrpc":"2.0","method":"context","id":1}`, "de", `{"code":-32602,"message":"Ungültige Parameter: name"}`},
+		{`{"jsonrpc":"2.0","method":"context","id":1}`, "", `{"code":-32602,"message":"Invalid method parameter(s): name"}`},
+	}
+
+	for i, tt := range tests {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		r.Header.Set("Accept-Language", tt.acceptLanguage)
+		rw := httptest.NewRecorder()
+		server.ServeHTTP(rw, r)
+
+		var resp struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(rw.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		}
+		if got := string(resp.Error); got != tt.expect {
+			t.Errorf("%d: expected error %s, got %s", i, tt.expect, got)
+		}
+	}
+}
+
+func TestServerTranslatorBatch(t *testing.T) {
+	server := jsonrpc.NewServer(
+		jsonrpc.Handlers{"encoded": HandlererFunc(encodedNotFoundHandler)},
+		jsonrpc.ServerTranslator(newTestCatalog()),
+	)
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`[{"jsonrpc":"2.0","method":"missing","id":1},{"jsonrpc":"2.0","method":"encoded","id":2}]`))
+	r.Header.Set("Accept-Language", "es, de;q=0.5")
+	rw := httptest.NewRecorder()
+	server.ServeHTTP(rw, r)
+
+	if got, expect := strings.TrimSpace(rw.Body.String()), `[{"jsonrpc":"2.0","error":{"code":-32601,"message":"El método no existe"},"id":1},{"jsonrpc":"2.0","error":{"code":4040,"message":"Nicht gefunden"},"id":2}]`; got != expect {
+		t.Errorf("Expected response %s, got %s", expect, got)
+	}
+}
+
+func TestTranslatorFunc(t *testing.T) {
+	var langs []string
+	translator := jsonrpc.TranslatorFunc(func(lang string, code int) (string, bool) {
+		langs = append(langs, lang)
+		return "", false
+	})
+	server := jsonrpc.NewServer(jsonrpc.Handlers{}, jsonrpc.ServerTranslator(translator))
+
+	r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"missing","id":1}`))
+	r.Header.Set("Accept-Language", "en;q=0.1, *;q=0.5, zh-Hant-TW")
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got, expect := strings.Join(langs, " "), "zh-hant-tw zh-hant zh en"; got != expect {
+		t.Errorf("Expected languages %q, got %q", expect, got)
+	}
+}
@@ -0,0 +1,434 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationFunc validates the value of the struct field, param is the
+// parameter of the rule in the validate tag, e.g. "1" for "min=1". The
+// message of the returned error is the reason of the Violation.
+type ValidationFunc func(value interface{}, param string) error
+
+// Validator validates the fields of structs according to their validate tags,
+// e.g.
+//
+//	type ListRequest struct {
+//		Query string `json:"query" validate:"required"`
+//		Limit int    `json:"limit" validate:"min=1,max=100"`
+//		Order string `json:"order" validate:"oneof=asc desc"`
+//	}
+//
+// The rules are separated by comma and the parameter follows the equals sign.
+// Nested structs, including elements of slices and maps, are validated too.
+// Nil pointers are validated only by the required rule.
+//
+// The built-in rules are:
+//
+//	required   the value is not the zero value
+//	omitempty  the other rules are skipped if the value is the zero value
+//	min, max   the bounds of numbers, of the length of strings in characters
+//	           and of the number of items of slices and maps
+//	len        the exact length of strings, slices and maps
+//	oneof      the value is one of the space separated values
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]rule
+
+	// checked are the results of Check of the validated types
+	checked map[reflect.Type]error
+}
+
+// rule validates the value of the field
+type rule func(v reflect.Value, param string) error
+
+// DefaultValidator is the Validator of the Handler, unless it is set by
+// HandlerValidator
+var DefaultValidator = NewValidator()
+
+// NewValidator returns the Validator with the built-in rules
+func NewValidator() *Validator {
+	return &Validator{
+		rules: map[string]rule{
+			"required": validateRequired,
+			"min":      validateMin,
+			"max":      validateMax,
+			"len":      validateLen,
+			"oneof":    validateOneOf,
+		},
+		checked: map[reflect.Type]error{},
+	}
+}
+
+// Register registers the custom rule, it replaces the rule of the same name
+func (v *Validator) Register(name string, f ValidationFunc) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = func(value reflect.Value, param string) error {
+		return f(value.Interface(), param)
+	}
+	v.checked = map[reflect.Type]error{}
+}
+
+// HandlerValidator sets the Validator of the request decoded by the
+// DecodeRequestFunc. Nil disables the validation.
+func HandlerValidator(v *Validator) HandlerOption {
+	return func(s *Handler) { s.validator = v }
+}
+
+// Check returns an error if the validate tags of the struct type of the
+// value, including the nested structs, refer to unknown rules
+func (v *Validator) Check(value interface{}) error {
+	return v.checkType(reflect.TypeOf(value), map[reflect.Type]bool{})
+}
+
+func (v *Validator) checkType(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _ := parseJSONTag(tag)
+
+		if !(f.Anonymous && name == "") {
+			if f.PkgPath != "" {
+				continue
+			}
+			if err := v.checkTag(f.Tag.Get("validate")); err != nil {
+				return fmt.Errorf("%s.%s: %v", t, f.Name, err)
+			}
+		}
+		if err := v.checkType(f.Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOnce returns the cached result of Check of the type
+func (v *Validator) checkOnce(t reflect.Type) error {
+	v.mu.RLock()
+	err, ok := v.checked[t]
+	v.mu.RUnlock()
+	if ok {
+		return err
+	}
+
+	err = v.checkType(t, map[reflect.Type]bool{})
+	v.mu.Lock()
+	v.checked[t] = err
+	v.mu.Unlock()
+	return err
+}
+
+// checkTag returns an error if the tag refers to unknown rule
+func (v *Validator) checkTag(tag string) error {
+	if tag == "" || tag == "-" {
+		return nil
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, r := range strings.Split(tag, ",") {
+		name := r
+		if i := strings.Index(r, "="); i != -1 {
+			name = r[:i]
+		}
+		if _, ok := v.rules[name]; !ok && name != "omitempty" {
+			return fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+	return nil
+}
+
+// Validate validates the struct, or pointer to struct, and returns
+// InvalidParamsError with the InvalidParamsData listing all the violations.
+// The paths of the violations are built from the JSON names of the fields.
+// Other values are valid. The type of the value is checked by Check once, an
+// error other than InvalidParamsError is returned for every value of the type
+// whose tags, including the tags of nil nested structs, refer to unknown
+// rules.
+func (v *Validator) Validate(value interface{}) error {
+	if err := v.checkOnce(reflect.TypeOf(value)); err != nil {
+		return err
+	}
+
+	sv := &structValidator{Validator: v}
+	if err := sv.validate(reflect.ValueOf(value), ""); err != nil {
+		return err
+	}
+	return sv.params.Err()
+}
+
+type structValidator struct {
+	*Validator
+	params InvalidParamsBuilder
+}
+
+func (sv *structValidator) validate(v reflect.Value, path string) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return sv.validateStruct(v, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := sv.validate(v.Index(i), path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := escapePointer(formatValue(iter.Key()))
+			if err := sv.validate(iter.Value(), path+"/"+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (sv *structValidator) validateStruct(v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _ := parseJSONTag(tag)
+
+		if f.Anonymous && name == "" {
+			if err := sv.validate(v.Field(i), path); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fieldPath := path + "/" + escapePointer(name)
+		valid, err := sv.validateField(v.Field(i), f.Tag.Get("validate"), fieldPath)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", t, f.Name, err)
+		}
+		if valid {
+			if err := sv.validate(v.Field(i), fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateField applies the rules of the tag to the field and reports whether
+// it is valid
+func (sv *structValidator) validateField(v reflect.Value, tag string, path string) (bool, error) {
+	if tag == "" || tag == "-" {
+		return true, nil
+	}
+
+	valid := true
+	for _, r := range strings.Split(tag, ",") {
+		name, param := r, ""
+		if i := strings.Index(r, "="); i != -1 {
+			name, param = r[:i], r[i+1:]
+		}
+		if name == "omitempty" {
+			if v.IsZero() {
+				break
+			}
+			continue
+		}
+
+		sv.mu.RLock()
+		f, ok := sv.rules[name]
+		sv.mu.RUnlock()
+		if !ok {
+			return false, fmt.Errorf("unknown validation rule %q", name)
+		}
+
+		if name != "required" && v.Kind() == reflect.Ptr && v.IsNil() {
+			continue
+		}
+		if err := f(v, param); err != nil {
+			sv.params.Add(path, name, err.Error(), rejectedValue(v))
+			valid = false
+		}
+	}
+	return valid, nil
+}
+
+// hasRule reports whether the validate tag has the rule
+func hasRule(tag, name string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if i := strings.Index(r, "="); i != -1 {
+			r = r[:i]
+		}
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectedValue returns the scalar value of the field, nil for the others
+func rejectedValue(v reflect.Value) interface{} {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Interface()
+	}
+	return nil
+}
+
+// indirect returns the value the pointers point to
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func validateRequired(v reflect.Value, _ string) error {
+	if v.IsZero() {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+// size returns the number of the value, the length of strings and the number
+// of items of slices and maps, the unit is used in the messages
+func size(v reflect.Value) (n float64, unit string, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters long", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items", true
+	}
+	return 0, "", false
+}
+
+// bound parses the param of the rule and the size of the value
+func bound(v reflect.Value, param string) (n, limit float64, unit string, err error) {
+	limit, err = strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid parameter %q", param)
+	}
+	n, unit, ok := size(indirect(v))
+	if !ok {
+		return 0, 0, "", fmt.Errorf("cannot be compared with %s", param)
+	}
+	return n, limit, unit, nil
+}
+
+func validateMin(v reflect.Value, param string) error {
+	n, limit, unit, err := bound(v, param)
+	if err != nil {
+		return err
+	}
+	if n < limit {
+		if unit == " items" {
+			return fmt.Errorf("must have at least %s items", param)
+		}
+		if unit == "" {
+			return fmt.Errorf("must be greater than or equal to %s", param)
+		}
+		return fmt.Errorf("must be at least %s%s", param, unit)
+	}
+	return nil
+}
+
+func validateMax(v reflect.Value, param string) error {
+	n, limit, unit, err := bound(v, param)
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		if unit == " items" {
+			return fmt.Errorf("must have at most %s items", param)
+		}
+		if unit == "" {
+			return fmt.Errorf("must be less than or equal to %s", param)
+		}
+		return fmt.Errorf("must be at most %s%s", param, unit)
+	}
+	return nil
+}
+
+func validateLen(v reflect.Value, param string) error {
+	n, limit, unit, err := bound(v, param)
+	if err != nil {
+		return err
+	}
+	if unit == "" {
+		return errors.New("has no length")
+	}
+	if n != limit {
+		if unit == " items" {
+			return fmt.Errorf("must have exactly %s items", param)
+		}
+		return fmt.Errorf("must be exactly %s%s", param, unit)
+	}
+	return nil
+}
+
+func validateOneOf(v reflect.Value, param string) error {
+	value := formatValue(indirect(v))
+	allowed := strings.Fields(param)
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", encodeValues(allowed))
+}
+
+// formatValue formats the scalar value as it is written in the tag
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.String:
+		return v.String()
+	}
+	return ""
+}
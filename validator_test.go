@@ -0,0 +1,187 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonrpc "github.com/fredipevcin/gokit-jsonrpc"
+)
+
+type validatedPaging struct {
+	Limit int `json:"limit" validate:"min=1,max=100"`
+}
+
+type validatedItem struct {
+	SKU      string  `json:"sku" validate:"required,sku"`
+	Quantity float64 `json:"quantity" validate:"min=0.5"`
+}
+
+type validatedRequest struct {
+	validatedPaging
+	Query   string                   `json:"query" validate:"required,max=5"`
+	Order   string                   `json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+	Tags    []string                 `json:"tags" validate:"len=2"`
+	Owner   *string                  `json:"owner" validate:"min=2"`
+	Items   []validatedItem          `json:"items" validate:"required"`
+	ByName  map[string]validatedItem `json:"byName"`
+	Skipped string                   `json:"-" validate:"required"`
+}
+
+func newTestValidator() *jsonrpc.Validator {
+	v := jsonrpc.NewValidator()
+	v.Register("sku", func(value interface{}, _ string) error {
+		if s, _ := value.(string); !strings.HasPrefix(s, "SKU-") {
+			return errors.New("must start with SKU-")
+		}
+		return nil
+	})
+	return v
+}
+
+func TestValidator(t *testing.T) {
+	v := newTestValidator()
+	owner := "x"
+
+	tests := []struct {
+		value  interface{}
+		expect []jsonrpc.Violation
+	}{
+		{
+			value: &validatedRequest{
+				validatedPaging: validatedPaging{Limit: 10},
+				Query:           "žluť",
+				Order:           "asc",
+				Tags:            []string{"a", "b"},
+				Items:           []validatedItem{{SKU: "SKU-1", Quantity: 1}},
+			},
+		},
+		{
+			value: validatedRequest{Order: "up", Owner: &owner, ByName: map[string]validatedItem{"a/b": {SKU: "SKU-1"}}},
+			expect: []jsonrpc.Violation{
+				{Path: "/limit", Code: "min", Message: "must be greater than or equal to 1", Value: 0},
+				{Path: "/query", Code: "required", Message: "is required", Value: ""},
+				{Path: "/order", Code: "oneof", Message: `must be one of ["asc","desc"]`, Value: "up"},
+				{Path: "/tags", Code: "len", Message: "must have exactly 2 items"},
+				{Path: "/owner", Code: "min", Message: "must be at least 2 characters long", Value: "x"},
+				{Path: "/items", Code: "required", Message: "is required"},
+				{Path: "/byName/a~1b/quantity", Code: "min", Message: "must be greater than or equal to 0.5", Value: 0.0},
+			},
+		},
+		{
+			value: validatedRequest{
+				validatedPaging: validatedPaging{Limit: 101},
+				Query:           "too long",
+				Tags:            []string{"a", "b"},
+				Items:           []validatedItem{{SKU: "SKU-1", Quantity: 1}, {SKU: "1", Quantity: 1}},
+			},
+			expect: []jsonrpc.Violation{
+				{Path: "/limit", Code: "max", Message: "must be less than or equal to 100", Value: 101},
+				{Path: "/query", Code: "max", Message: "must be at most 5 characters long", Value: "too long"},
+				{Path: "/items/1/sku", Code: "sku", Message: "must start with SKU-", Value: "1"},
+			},
+		},
+		{value: "not a struct"},
+		{value: (*validatedRequest)(nil)},
+	}
+
+	for i, tt := range tests {
+		err := v.Validate(tt.value)
+		if tt.expect == nil {
+			if err != nil {
+				t.Errorf("%d: unexpected error %v", i, err)
+			}
+			continue
+		}
+
+		e, ok := err.(jsonrpc.Error)
+		if !ok || e.Code != jsonrpc.InvalidParamsError {
+			t.Errorf("%d: expected InvalidParamsError, got %v", i, err)
+			continue
+		}
+		data, _ := e.Data.(jsonrpc.InvalidParamsData)
+		if !reflect.DeepEqual(data.Violations, tt.expect) {
+			t.Errorf("%d: expected %v, got %v", i, tt.expect, data.Violations)
+		}
+	}
+}
+
+func TestValidatorUnknownRule(t *testing.T) {
+	type request struct {
+		Name string `validate:"required,uuid"`
+	}
+
+	err := jsonrpc.NewValidator().Validate(request{})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if _, ok := err.(jsonrpc.Error); ok {
+		t.Errorf("Expected internal error, got %v", err)
+	}
+
+	// the tags of nil nested structs are checked too
+	type parent struct {
+		Child *request `json:"child"`
+	}
+	v := jsonrpc.NewValidator()
+	if err := v.Validate(parent{}); err == nil || !strings.Contains(err.Error(), `"uuid"`) {
+		t.Errorf("Expected error of unknown rule uuid of the nested struct, got %v", err)
+	}
+	v.Register("uuid", func(interface{}, string) error { return nil })
+	if err := v.Validate(parent{}); err != nil {
+		t.Errorf("Unexpected error %v after the rule is registered", err)
+	}
+}
+
+func TestValidatorCheck(t *testing.T) {
+	if err := newTestValidator().Check(&validatedRequest{}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err := jsonrpc.NewValidator().Check([]validatedRequest{}); err == nil || !strings.Contains(err.Error(), `"sku"`) {
+		t.Errorf("Expected error of unknown rule sku of the nested struct, got %v", err)
+	}
+	if err := jsonrpc.NewValidator().Check(nil); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestHandlerValidatesRequest(t *testing.T) {
+	var calls int
+	e := func(context.Context, interface{}) (interface{}, error) {
+		calls++
+		return nil, nil
+	}
+	dec := func(_ context.Context, params json.RawMessage) (interface{}, error) {
+		var req validatedItem
+		err := json.Unmarshal(params, &req)
+		return req, err
+	}
+	enc := func(context.Context, interface{}) (json.RawMessage, error) { return nil, nil }
+
+	handler := jsonrpc.NewHandler(e, dec, enc, jsonrpc.HandlerValidator(newTestValidator()))
+	_, _, err := handler.ServeJSONRPC(context.Background(), http.Header{}, json.RawMessage(`{"sku":"SKU-1","quantity":0}`))
+	b, _ := json.Marshal(err)
+	if got, expect := string(b), `{"code":-32602,"message":"Invalid method parameter(s)","data":{"violations":[{"path":"/quantity","code":"min","message":"must be greater than or equal to 0.5","value":0}]}}`; got != expect {
+		t.Errorf("Expected %s, got %s", expect, got)
+	}
+
+	// the DefaultValidator does not know the rule sku
+	handler = jsonrpc.NewHandler(e, dec, enc)
+	_, _, err = handler.ServeJSONRPC(context.Background(), http.Header{}, json.RawMessage(`{"sku":"SKU-1","quantity":1}`))
+	if _, ok := err.(jsonrpc.Error); err == nil || ok {
+		t.Errorf("Expected internal error of unknown rule sku, got %v", err)
+	}
+
+	handler = jsonrpc.NewHandler(e, dec, enc, jsonrpc.HandlerValidator(nil))
+	if _, _, err := handler.ServeJSONRPC(context.Background(), http.Header{}, json.RawMessage(`{"sku":"1","quantity":0}`)); err != nil {
+		t.Errorf("Expected no validation, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call of the endpoint, got %d", calls)
+	}
+}